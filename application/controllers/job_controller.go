@@ -14,6 +14,9 @@ import (
 type IJobController interface {
 	EnqueueJob(ctx context.Context, r *http.Request) api.WebResponse
 	ResultJob(ctx context.Context, r *http.Request, jobId string) api.WebResponse
+	RerunJob(ctx context.Context, r *http.Request, jobId string) api.WebResponse
+	CompareJob(ctx context.Context, r *http.Request, jobId, otherJobId string) api.WebResponse
+	CandidateProfile(ctx context.Context, r *http.Request, jobId string) api.WebResponse
 }
 
 type jobController struct {
@@ -49,3 +52,24 @@ func (e *jobController) ResultJob(ctx context.Context, r *http.Request, jobId st
 	resp := e.jobService.ResultJob(ctx, jobId)
 	return resp
 }
+
+func (e *jobController) RerunJob(ctx context.Context, r *http.Request, jobId string) api.WebResponse {
+	request, err := helper.ParseJSONBodyRequest[models.RerunRequest](r)
+	if err != nil {
+		log.Println("error when parse body request")
+		return api.CreateWebResponse("invalid request", http.StatusBadRequest, nil, nil)
+	}
+
+	resp := e.jobService.RerunJob(ctx, jobId, request)
+	return resp
+}
+
+func (e *jobController) CompareJob(ctx context.Context, r *http.Request, jobId, otherJobId string) api.WebResponse {
+	resp := e.jobService.CompareJob(ctx, jobId, otherJobId)
+	return resp
+}
+
+func (e *jobController) CandidateProfile(ctx context.Context, r *http.Request, jobId string) api.WebResponse {
+	resp := e.jobService.CandidateProfile(ctx, jobId)
+	return resp
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/api"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services"
+)
+
+type IUsageController interface {
+	UsageReport(ctx context.Context, r *http.Request, from, to string) api.WebResponse
+}
+
+type usageController struct {
+	usageService services.IUsageService
+}
+
+func NewUsageController(usageService services.IUsageService) IUsageController {
+	return &usageController{
+		usageService: usageService,
+	}
+}
+
+func (u *usageController) UsageReport(ctx context.Context, r *http.Request, from, to string) api.WebResponse {
+	return u.usageService.UsageReport(ctx, from, to)
+}
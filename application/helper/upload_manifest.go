@@ -0,0 +1,43 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+const UploadManifestFilename = "manifest.json"
+
+var (
+	ErrReadUploadManifest = errors.New("error when read upload manifest")
+)
+
+func ReadUploadManifest(basePath, fileId string) (*models.UploadManifest, error) {
+	content, err := os.ReadFile(filepath.Join(basePath, fileId, UploadManifestFilename))
+	if err != nil {
+		return nil, ErrReadUploadManifest
+	}
+
+	var manifest models.UploadManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, ErrReadUploadManifest
+	}
+
+	return &manifest, nil
+}
+
+// UploadedDocumentPath resolves the stored file of an upload field, uploads made before
+// the manifest existed were always saved as <field>.pdf.
+func UploadedDocumentPath(basePath, fileId, field string) string {
+	manifest, err := ReadUploadManifest(basePath, fileId)
+	if err == nil {
+		if doc, ok := manifest.Documents[field]; ok && doc.Filename != "" {
+			return filepath.Join(basePath, fileId, doc.Filename)
+		}
+	}
+
+	return filepath.Join(basePath, fileId, field+".pdf")
+}
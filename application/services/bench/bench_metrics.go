@@ -0,0 +1,181 @@
+package service_bench
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+func parseBenchScore(value string) *float64 {
+	score, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &score
+}
+
+// benchMetrics compares every labelled metric of the cases with its expected range.
+func benchMetrics(results []models.BenchCaseResult) map[string]models.BenchMetric {
+	metrics := make(map[string]models.BenchMetric)
+	for _, name := range []string{models.BenchMetricCvMatchRate, models.BenchMetricProjectScore} {
+		var metric models.BenchMetric
+		var actual, expected []float64
+		for _, result := range results {
+			want, ok := result.Expected[name]
+			if !ok {
+				continue
+			}
+			metric.Cases++
+
+			score := result.Scores[name]
+			if score == nil {
+				metric.Failed++
+				continue
+			}
+			metric.Scored++
+			if !result.InRange[name] {
+				metric.OutOfRange++
+			}
+
+			middle := (want.Min + want.Max) / 2
+			metric.Mae += math.Abs(*score - middle)
+			actual = append(actual, *score)
+			expected = append(expected, middle)
+		}
+
+		if metric.Cases == 0 {
+			continue
+		}
+		if metric.Scored > 0 {
+			metric.Mae /= float64(metric.Scored)
+		}
+		metric.Spearman = spearman(actual, expected)
+		metrics[name] = metric
+	}
+
+	return metrics
+}
+
+// spearman is the rank correlation of a and b, ties get their average rank. It is nil
+// when there are fewer than two pairs or either side has a single rank.
+func spearman(a, b []float64) *float64 {
+	if len(a) < 2 || len(a) != len(b) {
+		return nil
+	}
+
+	rankA, rankB := ranks(a), ranks(b)
+	meanA, meanB := mean(rankA), mean(rankB)
+	var cov, varA, varB float64
+	for idx := range rankA {
+		da, db := rankA[idx]-meanA, rankB[idx]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return nil
+	}
+
+	rho := cov / math.Sqrt(varA*varB)
+	return &rho
+}
+
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for idx := start; idx <= end; idx++ {
+			result[order[idx]] = rank
+		}
+		start = end + 1
+	}
+	return result
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// DiffReports compares current with a previous run of the same dataset, cases are
+// matched by name and listed when a score or its range check changed.
+func DiffReports(previous, current *models.BenchReport) *models.BenchDiff {
+	diff := &models.BenchDiff{
+		RunAt:   previous.RunAt,
+		Metrics: make(map[string]models.BenchMetricDelta),
+		Cases:   []models.BenchCaseDiff{},
+	}
+
+	for name, metric := range current.Metrics {
+		before, ok := previous.Metrics[name]
+		if !ok {
+			continue
+		}
+		delta := models.BenchMetricDelta{
+			MaeDelta:        metric.Mae - before.Mae,
+			OutOfRangeDelta: metric.OutOfRange - before.OutOfRange,
+		}
+		if metric.Spearman != nil && before.Spearman != nil {
+			spearmanDelta := *metric.Spearman - *before.Spearman
+			delta.SpearmanDelta = &spearmanDelta
+		}
+		diff.Metrics[name] = delta
+	}
+
+	previousCases := make(map[string]models.BenchCaseResult)
+	for _, result := range previous.Cases {
+		previousCases[result.Name] = result
+	}
+	for _, result := range current.Cases {
+		before, ok := previousCases[result.Name]
+		if !ok {
+			continue
+		}
+
+		metrics := make([]string, 0, len(result.Expected))
+		for metric := range result.Expected {
+			metrics = append(metrics, metric)
+		}
+		slices.Sort(metrics)
+		for _, metric := range metrics {
+			was, now := before.Scores[metric], result.Scores[metric]
+			if sameScore(was, now) && before.InRange[metric] == result.InRange[metric] {
+				continue
+			}
+			diff.Cases = append(diff.Cases, models.BenchCaseDiff{
+				Name:            result.Name,
+				Metric:          metric,
+				Previous:        was,
+				Current:         now,
+				PreviousInRange: before.InRange[metric],
+				CurrentInRange:  result.InRange[metric],
+			})
+		}
+	}
+
+	return diff
+}
+
+func sameScore(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,149 @@
+package service_bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/helper"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+const benchCaseFilename = "case.json"
+
+var (
+	ErrEmptyDataset    = errors.New("error dataset has no cases")
+	ErrInvalidCase     = errors.New("error invalid bench case")
+	ErrCaseDocMissing  = errors.New("error bench case document not found")
+	ErrStageBenchInput = errors.New("error stage bench case documents")
+)
+
+// LoadDataset reads every sub directory of dir holding a case.json. The cv and report
+// fields name the documents next to it, by default the files named cv.* and report.*.
+func LoadDataset(dir string) ([]models.BenchCase, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []models.BenchCase{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		caseDir := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(filepath.Join(caseDir, benchCaseFilename))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		var benchCase models.BenchCase
+		if err := json.Unmarshal(content, &benchCase); err != nil {
+			return nil, fmt.Errorf("%w %s: %s", ErrInvalidCase, entry.Name(), err.Error())
+		}
+		if benchCase.Name == "" {
+			benchCase.Name = entry.Name()
+		}
+		if benchCase.JobTitle == "" || len(benchCase.Expected) == 0 {
+			return nil, fmt.Errorf("%w %s: job_title and expected are required", ErrInvalidCase, entry.Name())
+		}
+
+		if benchCase.Cv, err = caseDocument(caseDir, benchCase.Cv, "cv"); err != nil {
+			return nil, fmt.Errorf("%w %s: %s", ErrCaseDocMissing, entry.Name(), err.Error())
+		}
+		if benchCase.Report, err = caseDocument(caseDir, benchCase.Report, "report"); err != nil {
+			return nil, fmt.Errorf("%w %s: %s", ErrCaseDocMissing, entry.Name(), err.Error())
+		}
+
+		cases = append(cases, benchCase)
+	}
+
+	if len(cases) == 0 {
+		return nil, ErrEmptyDataset
+	}
+	slices.SortFunc(cases, func(a, b models.BenchCase) int { return strings.Compare(a.Name, b.Name) })
+	return cases, nil
+}
+
+// caseDocument resolves the path of a case document, name is relative to the case dir.
+func caseDocument(caseDir, name, prefix string) (string, error) {
+	if name != "" {
+		path := filepath.Join(caseDir, name)
+		if _, err := os.Stat(path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(caseDir, prefix+".*"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no %s.* file", prefix)
+	}
+	return matches[0], nil
+}
+
+// stageUpload copies the case documents into uploadDir/fileId with a manifest, the way
+// the upload endpoint stores them, so the consumer reads them unchanged.
+func stageUpload(uploadDir, fileId string, benchCase models.BenchCase) error {
+	folder := filepath.Join(uploadDir, fileId)
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		return ErrStageBenchInput
+	}
+
+	manifest := &models.UploadManifest{
+		FileId:    fileId,
+		Documents: make(map[string]models.DocumentValidation),
+	}
+	for field, source := range map[string]string{"cv_file": benchCase.Cv, "report_file": benchCase.Report} {
+		filename := field + filepath.Ext(source)
+		if err := copyFile(source, filepath.Join(folder, filename)); err != nil {
+			return ErrStageBenchInput
+		}
+		manifest.Documents[field] = models.DocumentValidation{
+			Field:        field,
+			Filename:     filename,
+			OriginalName: filepath.Base(source),
+			Valid:        true,
+			Code:         models.ValidationOk,
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ErrStageBenchInput
+	}
+	if err := os.WriteFile(filepath.Join(folder, helper.UploadManifestFilename), content, 0o644); err != nil {
+		return ErrStageBenchInput
+	}
+
+	return nil
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,159 @@
+package service_bench
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	service_consumer "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/consumer"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	LlmGemini = "gemini"
+	// LlmReplay answers from responses recorded by an earlier run, no model is called
+	LlmReplay = "replay"
+)
+
+type IEvalBench interface {
+	Run(ctx context.Context, dataset string) (*models.BenchReport, error)
+}
+
+// ServiceFactory builds the consumer pipeline under test on the bench repositories, the
+// documents of every case are staged in uploadBasePath.
+type ServiceFactory func(
+	jobs repository.ICvEvaluatorJobRepository,
+	profiles repository.ICandidateProfileRepository,
+	uploadBasePath string,
+) service_consumer.ICvEvaluatorConsumerService
+
+type BenchOption func(*evalBench)
+
+type evalBench struct {
+	factory ServiceFactory
+	llm     string
+	model   string
+	noCache bool
+}
+
+func NewEvalBench(factory ServiceFactory, opts ...BenchOption) IEvalBench {
+	bench := &evalBench{factory: factory}
+	for _, opt := range opts {
+		opt(bench)
+	}
+
+	return bench
+}
+
+// Options
+// WithBenchLlm names the model client of the run in the report, e.g. gemini or replay
+func WithBenchLlm(llm string) BenchOption {
+	return func(b *evalBench) {
+		b.llm = llm
+	}
+}
+
+// WithBenchModel sets the job model of every case, empty keeps the client model
+func WithBenchModel(model string) BenchOption {
+	return func(b *evalBench) {
+		b.model = model
+	}
+}
+
+// WithBenchNoCache skips the response cache for every case
+func WithBenchNoCache(noCache bool) BenchOption {
+	return func(b *evalBench) {
+		b.noCache = noCache
+	}
+}
+
+// Run evaluates every case of the dataset one after another, the jobs are kept in memory
+// so a run never touches the job table.
+func (b *evalBench) Run(ctx context.Context, dataset string) (*models.BenchReport, error) {
+	cases, err := LoadDataset(dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadDir, err := os.MkdirTemp("", "eval-bench-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(uploadDir)
+
+	jobs := repository.NewMemoryCvEvaluatorJobRepository()
+	service := b.factory(jobs, repository.NewMemoryCandidateProfileRepository(), uploadDir)
+
+	results := make([]models.BenchCaseResult, 0, len(cases))
+	for _, benchCase := range cases {
+		result := b.runCase(ctx, service, jobs, uploadDir, benchCase)
+		log.Printf("bench case %s %s", benchCase.Name, result.Status)
+		results = append(results, result)
+	}
+
+	return &models.BenchReport{
+		Dataset: dataset,
+		Llm:     b.llm,
+		RunAt:   time.Now().UTC().Format(time.RFC3339),
+		Metrics: benchMetrics(results),
+		Cases:   results,
+	}, nil
+}
+
+func (b *evalBench) runCase(
+	ctx context.Context,
+	service service_consumer.ICvEvaluatorConsumerService,
+	jobs repository.ICvEvaluatorJobRepository,
+	uploadDir string,
+	benchCase models.BenchCase,
+) models.BenchCaseResult {
+	result := models.BenchCaseResult{
+		Name:     benchCase.Name,
+		Status:   models.StatusFailed,
+		Scores:   make(map[string]*float64),
+		Expected: benchCase.Expected,
+		InRange:  make(map[string]bool),
+	}
+
+	jobId := uuid.New().String()
+	if err := stageUpload(uploadDir, jobId, benchCase); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	job := &dao.CvEvaluatorJob{
+		JobId:         jobId,
+		JobTitle:      benchCase.JobTitle,
+		FileId:        jobId,
+		Model:         b.model,
+		PromptVersion: models.DefaultPromptVersion,
+		Status:        models.StatusQueued,
+		NoCache:       b.noCache,
+	}
+	if err := jobs.CreateJobItem(ctx, job); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if err := service.RunningJob(ctx, jobId); err != nil {
+		result.Error = err.Error()
+	}
+
+	done, err := jobs.GetByJobId(ctx, jobId)
+	if err != nil {
+		return result
+	}
+	result.Status = done.Status
+	result.Scores[models.BenchMetricCvMatchRate] = parseBenchScore(done.CvMatchRate)
+	result.Scores[models.BenchMetricProjectScore] = parseBenchScore(done.ProjectScore)
+	for metric, expected := range benchCase.Expected {
+		score := result.Scores[metric]
+		result.InRange[metric] = score != nil && *score >= expected.Min && *score <= expected.Max
+	}
+
+	return result
+}
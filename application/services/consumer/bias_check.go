@@ -0,0 +1,109 @@
+package service_consumer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	biasblinder "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/bias-blinder"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+type BiasMode string
+
+const (
+	BiasModeOff   BiasMode = "off"
+	BiasModeBlind BiasMode = "blind"
+)
+
+const DefaultBiasShiftThreshold = 0.1
+
+// blindCvInput strips demographic signals and the candidate name from the CV prompt input
+// when the blind mode is on.
+func (w *cvEvaluatorConsumerService) blindCvInput(job *dao.CvEvaluatorJob, cvInput, candidateName string) string {
+	if w.biasMode != BiasModeBlind {
+		job.BiasCheck = nil
+		job.BiasFlagged = false
+		return cvInput
+	}
+
+	blinded, attributes := w.blinder.Blind(cvInput, candidateName)
+	check := &models.BiasCheck{
+		Mode:              string(w.biasMode),
+		BlindedAttributes: make([]string, 0, len(attributes)),
+		Sampled:           biasblinder.Sampled(job.JobId, w.counterfactualRate),
+		Threshold:         w.biasShiftThreshold,
+	}
+	for _, attribute := range attributes {
+		check.BlindedAttributes = append(check.BlindedAttributes, string(attribute))
+	}
+	job.BiasCheck = check
+	job.BiasFlagged = false
+
+	w.traceEvent(job, "bias_blinding", "blinded "+strings.Join(check.BlindedAttributes, ", "))
+	return blinded
+}
+
+// counterfactualCheck scores the CV text from before blinding and the same text with the
+// name and gender signals swapped, each with as many samples as the job score, and flags
+// the job when the two match rates differ more than the threshold. The two prompts only
+// differ by the swap, so blinding and the sampling of the job score do not count in the
+// shift. Only sampled jobs send the unblinded text to the model. It never fails the job.
+func (c *cvEvaluatorConsumerService) counterfactualCheck(
+	ctx context.Context,
+	job *dao.CvEvaluatorJob,
+	cvText, candidateName string,
+	jobDescription, cvRubric []models.ChromaSearchResult,
+	opts []geminiclient.GenerateOption,
+) {
+	check := job.BiasCheck
+	if check == nil || !check.Sampled {
+		return
+	}
+
+	counterfactual := biasblinder.BuildCounterfactual(cvText, candidateName)
+	original, err := c.counterfactualScore(ctx, job, cvText, jobDescription, cvRubric, opts)
+	if err != nil {
+		c.traceEvent(job, "bias_counterfactual", "failed, original score: "+err.Error())
+		return
+	}
+	score, err := c.counterfactualScore(ctx, job, counterfactual.Text, jobDescription, cvRubric, opts)
+	if err != nil {
+		c.traceEvent(job, "bias_counterfactual", "failed, counterfactual score: "+err.Error())
+		return
+	}
+
+	shift := math.Abs(score - original)
+	check.Variant = counterfactual.Variant
+	check.OriginalScore = &original
+	check.CounterfactualScore = &score
+	check.Shift = &shift
+	check.Flagged = shift > check.Threshold
+	job.BiasFlagged = check.Flagged
+
+	c.traceEvent(job, "bias_counterfactual", fmt.Sprintf("%s, score %.2f vs %.2f, shift %.2f", counterfactual.Variant, original, score, shift))
+}
+
+// counterfactualScore scores one side of the check, both sides are fitted to the same
+// budget and cached under their own text.
+func (c *cvEvaluatorConsumerService) counterfactualScore(
+	ctx context.Context,
+	job *dao.CvEvaluatorJob,
+	cvText string,
+	jobDescription, cvRubric []models.ChromaSearchResult,
+	opts []geminiclient.GenerateOption,
+) (float64, error) {
+	input := c.fitCandidateText(job, "bias_counterfactual_budget", cvText)
+	prompt := c.buildCvEvaluatorPrompt(job.JobTitle, input, jobDescription, cvRubric)
+	options := append(slices.Clone(opts), c.cacheKeyOption(job, StageCvScoring, input, jobDescription, cvRubric))
+	return c.medianScore(ctx, job, prompt, options, cvScoreScale)
+}
+
+func parseScore(value string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(value), 64)
+}
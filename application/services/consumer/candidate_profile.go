@@ -0,0 +1,199 @@
+package service_consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+	piiredactor "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/pii-redactor"
+)
+
+type CvPromptInput string
+
+const (
+	// CvPromptInputFull sends the whole extracted CV text
+	CvPromptInputFull CvPromptInput = "full"
+	// CvPromptInputProfile sends only the compact candidate profile
+	CvPromptInputProfile CvPromptInput = "profile"
+	// CvPromptInputBoth sends the compact profile followed by the extracted CV text
+	CvPromptInputBoth CvPromptInput = "both"
+)
+
+var ErrInvalidCandidateProfile = errors.New("invalid candidate profile response")
+
+// extractCandidateProfile asks the model to parse the redacted CV into a typed profile,
+// the stored profile has the PII put back while the returned one keeps the placeholders
+// for the prompt. The evaluation keeps going on the CV text when this stage fails.
+func (c *cvEvaluatorConsumerService) extractCandidateProfile(ctx context.Context, job *dao.CvEvaluatorJob, extractedCv string, vault *piiredactor.Vault, opts []geminiclient.GenerateOption) (*models.CandidateProfile, error) {
+	profileOpts := append([]geminiclient.GenerateOption{}, opts...)
+	profileOpts = append(profileOpts, geminiclient.WithResponseMIMEType("application/json"))
+	resp, err := c.gemini.GenerateContent(ctx, job.JobTitle, c.buildCandidateProfilePrompt(extractedCv), profileOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := parseCandidateProfile(resp)
+	if err != nil {
+		return nil, err
+	}
+	profile.JobId = job.JobId
+	profile.FileId = job.FileId
+
+	if err := c.candidateProfile.UpsertProfile(ctx, toCandidateProfileDao(restoreCandidateProfile(profile, vault))); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (w *cvEvaluatorConsumerService) buildCandidateProfilePrompt(extractedCv string) string {
+	prompt := "Extract the candidate profile from this CV.\n"
+	prompt += "Use empty strings or empty lists for anything the CV does not mention, do not guess.\n"
+	prompt += "Dates use YYYY-MM or YYYY, an ongoing position has end_date \"present\".\n"
+	prompt += "\n-----\n"
+	prompt += "Candidate CV: \n" + extractedCv
+	prompt += "\n-----\n"
+	prompt += "Return only JSON as:\n"
+	prompt += `{"name": "", "email": "", "phone": "", "location": "", "years_of_experience": 0,` + "\n"
+	prompt += ` "employment": [{"company": "", "title": "", "start_date": "", "end_date": "", "summary": ""}],` + "\n"
+	prompt += ` "education": [{"institution": "", "degree": "", "field": "", "start_date": "", "end_date": ""}],` + "\n"
+	prompt += ` "skills": [], "certifications": [], "links": []}` + "\n"
+	return prompt
+}
+
+// parseCandidateProfile accepts the JSON object with or without a markdown code fence.
+func parseCandidateProfile(resp string) (*models.CandidateProfile, error) {
+	resp = strings.TrimSpace(resp)
+	start := strings.Index(resp, "{")
+	end := strings.LastIndex(resp, "}")
+	if start < 0 || end < start {
+		return nil, ErrInvalidCandidateProfile
+	}
+
+	var profile models.CandidateProfile
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &profile); err != nil {
+		return nil, ErrInvalidCandidateProfile
+	}
+
+	return &profile, nil
+}
+
+// renderCandidateProfile is the compact text form of the profile used in the CV prompt.
+func renderCandidateProfile(profile *models.CandidateProfile) string {
+	var sb strings.Builder
+	if profile.Name != "" {
+		sb.WriteString("Name: " + profile.Name + "\n")
+	}
+	if profile.Location != "" {
+		sb.WriteString("Location: " + profile.Location + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("Years of experience: %g\n", profile.YearsOfExperience))
+
+	if len(profile.Employment) > 0 {
+		sb.WriteString("Employment:\n")
+		for _, employment := range profile.Employment {
+			sb.WriteString(fmt.Sprintf("- %s at %s (%s - %s)", employment.Title, employment.Company, employment.StartDate, employment.EndDate))
+			if employment.Summary != "" {
+				sb.WriteString(": " + employment.Summary)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(profile.Education) > 0 {
+		sb.WriteString("Education:\n")
+		for _, education := range profile.Education {
+			sb.WriteString(fmt.Sprintf("- %s %s, %s (%s - %s)\n", education.Degree, education.Field, education.Institution, education.StartDate, education.EndDate))
+		}
+	}
+
+	if len(profile.Skills) > 0 {
+		sb.WriteString("Skills: " + strings.Join(profile.Skills, ", ") + "\n")
+	}
+	if len(profile.Certifications) > 0 {
+		sb.WriteString("Certifications: " + strings.Join(profile.Certifications, ", ") + "\n")
+	}
+	if len(profile.Links) > 0 {
+		sb.WriteString("Links: " + strings.Join(profile.Links, ", ") + "\n")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// cvPromptText picks what the CV prompt sees, the raw text is the fallback when no
+// profile could be extracted.
+func (w *cvEvaluatorConsumerService) cvPromptText(extractedCv string, profile *models.CandidateProfile) string {
+	if profile == nil {
+		return extractedCv
+	}
+
+	switch w.cvPromptInput {
+	case CvPromptInputProfile:
+		return "Candidate Profile:\n" + renderCandidateProfile(profile)
+	case CvPromptInputBoth:
+		return "Candidate Profile:\n" + renderCandidateProfile(profile) + "\n\nFull CV:\n" + extractedCv
+	default:
+		return extractedCv
+	}
+}
+
+func restoreCandidateProfile(profile *models.CandidateProfile, vault *piiredactor.Vault) *models.CandidateProfile {
+	restored := *profile
+	restored.Name = vault.Restore(profile.Name)
+	restored.Email = vault.Restore(profile.Email)
+	restored.Phone = vault.Restore(profile.Phone)
+	restored.Location = vault.Restore(profile.Location)
+	restored.Links = restoreAll(profile.Links, vault)
+
+	restored.Employment = make([]models.EmploymentHistory, len(profile.Employment))
+	for idx, employment := range profile.Employment {
+		employment.Company = vault.Restore(employment.Company)
+		employment.Title = vault.Restore(employment.Title)
+		employment.StartDate = vault.Restore(employment.StartDate)
+		employment.EndDate = vault.Restore(employment.EndDate)
+		employment.Summary = vault.Restore(employment.Summary)
+		restored.Employment[idx] = employment
+	}
+
+	restored.Education = make([]models.EducationHistory, len(profile.Education))
+	for idx, education := range profile.Education {
+		education.Institution = vault.Restore(education.Institution)
+		education.Degree = vault.Restore(education.Degree)
+		education.Field = vault.Restore(education.Field)
+		education.StartDate = vault.Restore(education.StartDate)
+		education.EndDate = vault.Restore(education.EndDate)
+		restored.Education[idx] = education
+	}
+
+	return &restored
+}
+
+func restoreAll(values []string, vault *piiredactor.Vault) []string {
+	restored := make([]string, len(values))
+	for idx, value := range values {
+		restored[idx] = vault.Restore(value)
+	}
+	return restored
+}
+
+func toCandidateProfileDao(profile *models.CandidateProfile) *dao.CandidateProfile {
+	return &dao.CandidateProfile{
+		JobId:             profile.JobId,
+		FileId:            profile.FileId,
+		Name:              profile.Name,
+		Email:             profile.Email,
+		Phone:             profile.Phone,
+		Location:          profile.Location,
+		YearsOfExperience: profile.YearsOfExperience,
+		Employment:        profile.Employment,
+		Education:         profile.Education,
+		Skills:            profile.Skills,
+		Certifications:    profile.Certifications,
+		Links:             profile.Links,
+	}
+}
@@ -4,27 +4,68 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"path"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/helper"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	biasblinder "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/bias-blinder"
 	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
 	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
 	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
+	piiredactor "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/pii-redactor"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/retriever"
 )
 
+const (
+	uploadBasePath = "uploaded-file"
+	// traceDetailLimit caps the prompt text kept in a trace event
+	traceDetailLimit = 4000
+)
+
+// KnowledgeCollections are the collections an evaluation retrieves from.
+var KnowledgeCollections = []string{"job_description", "cv_rubric", "case_study_brief", "project_report_rubric"}
+
 type ICvEvaluatorConsumerService interface {
 	RunningJob(ctx context.Context, jobId string) error
 }
 
+type CvEvaluatorOption func(*cvEvaluatorConsumerService)
+
 type cvEvaluatorConsumerService struct {
-	gemini      geminiclient.IGeminiClient
-	chroma      chromaclient.IChromaClient
-	ingest      ingestdocument.IIngestFile
-	cvEvaluator repository.ICvEvaluatorJobRepository
+	gemini           geminiclient.IGeminiClient
+	chroma           chromaclient.IChromaClient
+	ingest           ingestdocument.IIngestFile
+	cvEvaluator      repository.ICvEvaluatorJobRepository
+	candidateProfile repository.ICandidateProfileRepository
+	uploadBasePath   string
+	cvPromptInput    CvPromptInput
+	redactor         piiredactor.IRedactor
+	vaultStore       piiredactor.IVaultStore
+
+	retriever         retriever.IRetriever
+	retrievalStrategy RetrievalStrategy
+	contextTokens     int
+	contextTokenizer  ingestdocument.ITokenizer
+	promptBudget      PromptBudget
+
+	stageSettings     map[PipelineStage]models.GenerationSettings
+	scoringSamples    int
+	varianceThreshold float64
+	verificationMode  VerificationMode
+
+	usageRepository repository.ILlmUsageRepository
+	prices          geminiclient.PriceTable
+	usageMu         sync.Mutex
+
+	biasMode           BiasMode
+	blinder            biasblinder.IBlinder
+	counterfactualRate float64
+	biasShiftThreshold float64
 }
 
 func NewCvEvaluatorConsumerService(
@@ -32,12 +73,175 @@ func NewCvEvaluatorConsumerService(
 	chroma chromaclient.IChromaClient,
 	ingest ingestdocument.IIngestFile,
 	cvEvaluator repository.ICvEvaluatorJobRepository,
+	candidateProfile repository.ICandidateProfileRepository,
+	opts ...CvEvaluatorOption,
 ) ICvEvaluatorConsumerService {
-	return &cvEvaluatorConsumerService{
-		gemini:      gemini,
-		chroma:      chroma,
-		ingest:      ingest,
-		cvEvaluator: cvEvaluator,
+	service := &cvEvaluatorConsumerService{
+		gemini:           gemini,
+		chroma:           chroma,
+		ingest:           ingest,
+		cvEvaluator:      cvEvaluator,
+		candidateProfile: candidateProfile,
+		uploadBasePath:   uploadBasePath,
+		cvPromptInput:    CvPromptInputFull,
+		redactor:         piiredactor.NewRedactor(piiredactor.LevelNone),
+
+		retrievalStrategy: RetrievalStrategyTitle,
+		contextTokens:     DefaultContextTokens,
+		contextTokenizer:  ingestdocument.NewApproxTokenizer(),
+		promptBudget:      DefaultPromptBudget(),
+
+		scoringSamples:    1,
+		varianceThreshold: DefaultVarianceThreshold,
+		verificationMode:  VerificationOff,
+
+		biasMode:           BiasModeOff,
+		blinder:            biasblinder.NewBlinder(),
+		biasShiftThreshold: DefaultBiasShiftThreshold,
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	if service.retriever == nil {
+		service.retriever = retriever.NewRetriever(chroma)
+	}
+
+	return service
+}
+
+// Options
+func WithCvPromptInput(input CvPromptInput) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		switch input {
+		case CvPromptInputProfile, CvPromptInputBoth:
+			c.cvPromptInput = input
+		default:
+			c.cvPromptInput = CvPromptInputFull
+		}
+	}
+}
+
+// WithUploadBasePath reads the job documents from another upload directory
+func WithUploadBasePath(path string) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if path != "" {
+			c.uploadBasePath = path
+		}
+	}
+}
+
+// WithRetriever replaces the plain vector search used for the job description, case
+// study brief and rubrics.
+func WithRetriever(r retriever.IRetriever) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		c.retriever = r
+	}
+}
+
+// WithRetrievalStrategy picks how the knowledge base queries are built, contextTokens caps
+// the retrieved chunks of each prompt and falls back to DefaultContextTokens when zero.
+func WithRetrievalStrategy(strategy RetrievalStrategy, contextTokens int) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if strategy == RetrievalStrategyContent {
+			c.retrievalStrategy = RetrievalStrategyContent
+		} else {
+			c.retrievalStrategy = RetrievalStrategyTitle
+		}
+		if contextTokens > 0 {
+			c.contextTokens = contextTokens
+		}
+	}
+}
+
+// WithPromptBudget caps the tokens of each prompt section, zero fields keep the default.
+// Reports over the candidate budget are evaluated part by part and then combined.
+func WithPromptBudget(budget PromptBudget) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if budget.Brief > 0 {
+			c.promptBudget.Brief = budget.Brief
+		}
+		if budget.Rubric > 0 {
+			c.promptBudget.Rubric = budget.Rubric
+		}
+		if budget.Candidate > 0 {
+			c.promptBudget.Candidate = budget.Candidate
+		}
+	}
+}
+
+// WithStageSettings sets the model and sampling parameters of each pipeline stage, stages
+// left out use the client defaults with the job model.
+func WithStageSettings(settings map[PipelineStage]models.GenerationSettings) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		c.stageSettings = settings
+	}
+}
+
+// WithSelfConsistency scores the CV and the report with samples independent calls and
+// keeps the median, jobs whose samples vary more than varianceThreshold are flagged
+// low_confidence.
+func WithSelfConsistency(samples int, varianceThreshold float64) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if samples > 1 {
+			c.scoringSamples = samples
+		}
+		if varianceThreshold > 0 {
+			c.varianceThreshold = varianceThreshold
+		}
+	}
+}
+
+// WithFeedbackVerification checks every claim of the CV and report feedback against the
+// candidate documents and the retrieved chunks before the job completes, ungrounded
+// claims are annotated or the feedback is regenerated.
+func WithFeedbackVerification(mode VerificationMode) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		switch mode {
+		case VerificationAnnotate, VerificationRegenerate:
+			c.verificationMode = mode
+		default:
+			c.verificationMode = VerificationOff
+		}
+	}
+}
+
+// WithUsageTracking prices the tokens of every model call, the totals are kept on the job
+// and each call is recorded in repository when one is given.
+func WithUsageTracking(usageRepository repository.ILlmUsageRepository, prices geminiclient.PriceTable) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		c.usageRepository = usageRepository
+		c.prices = prices
+	}
+}
+
+// WithPiiRedaction replaces PII in the documents before any prompt is built, the
+// mapping of each job is kept in store when one is given.
+func WithPiiRedaction(redactor piiredactor.IRedactor, store piiredactor.IVaultStore) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if redactor != nil {
+			c.redactor = redactor
+		}
+		c.vaultStore = store
+	}
+}
+
+// WithBiasMitigation blinds demographic signals in the CV before scoring and re-scores a
+// sample of jobs with swapped name and gender signals, jobs shifting more than threshold
+// are flagged.
+func WithBiasMitigation(mode BiasMode, sampleRate, threshold float64) CvEvaluatorOption {
+	return func(c *cvEvaluatorConsumerService) {
+		if mode != BiasModeBlind {
+			c.biasMode = BiasModeOff
+			return
+		}
+
+		c.biasMode = BiasModeBlind
+		c.counterfactualRate = sampleRate
+		if threshold > 0 {
+			c.biasShiftThreshold = threshold
+		}
 	}
 }
 
@@ -54,86 +258,150 @@ func (c *cvEvaluatorConsumerService) RunningJob(ctx context.Context, jobId strin
 
 	// update to processing
 	job.Status = models.StatusProcessing
+	job.Trace = nil
 	c.cvEvaluator.UpdateJobByJobId(ctx, jobId, job)
 
+	if job.PromptVersion == "" {
+		job.PromptVersion = models.DefaultPromptVersion
+	}
+	if !slices.Contains(models.PromptVersions, job.PromptVersion) {
+		err = fmt.Errorf("unknown prompt version %s", job.PromptVersion)
+		c.jobFailToProcess(ctx, job, err)
+		return err
+	}
+	job.Usage = &models.JobUsage{}
+	job.GenerationSettings = make(map[string]models.GenerationSettings)
+	job.ScoreSpread = nil
+	job.LowConfidence = false
+	job.FeedbackVerification = nil
+	generateOptions := c.jobGenerateOptions(job)
+	rubricOptions := c.rubricQueryOptions(job)
+
 	// extract text from file
-	extractedCv, err := c.ingest.ExtractTextFromPdf(path.Join("uploaded-file", job.FileId, "cv_file.pdf"))
+	cvDocument, err := c.ingest.ExtractText(ctx, helper.UploadedDocumentPath(c.uploadBasePath, job.FileId, "cv_file"))
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
+	extractedCv := cvDocument.PromptText()
 
-	extractedReport, err := c.ingest.ExtractTextFromPdf(path.Join("uploaded-file", job.FileId, "report_file.pdf"))
+	reportDocument, err := c.ingest.ExtractText(ctx, helper.UploadedDocumentPath(c.uploadBasePath, job.FileId, "report_file"))
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
+	extractedReport := reportDocument.PromptText()
+
+	job.OcrSummary = c.ocrSummary(map[string]*ingestdocument.ExtractionResult{
+		"cv_file":     cvDocument,
+		"report_file": reportDocument,
+	})
+	c.traceEvent(job, "extract", fmt.Sprintf("cv %s %d chars, report %s %d chars", cvDocument.ContentType, len(extractedCv), reportDocument.ContentType, len(extractedReport)))
+
+	// Redact PII, nothing below this point sees the original text
+	vault := piiredactor.NewVault()
+	extractedCv = c.redactor.Redact(extractedCv, vault)
+	extractedReport = c.redactor.Redact(extractedReport, vault)
+	c.storeVault(ctx, job, vault)
+
+	// Candidate profile
+	profileOptions := append(c.stageOptions(job, StageCandidateProfile, generateOptions), c.cacheKeyOption(job, StageCandidateProfile, extractedCv))
+	profile, err := c.extractCandidateProfile(ctx, job, extractedCv, vault, profileOptions)
+	if err != nil {
+		log.Printf("failed to extract candidate profile for job %s: %s", job.JobId, err.Error())
+		c.traceEvent(job, "candidate_profile", "failed, "+err.Error())
+	} else {
+		c.traceEvent(job, "candidate_profile", renderCandidateProfile(profile))
+	}
 
 	// Evaluate CV
-	jobDescription, err := c.chroma.Query(ctx, "job_description", job.JobTitle+" "+"job description", 5)
+	cvGroups := cvQueryGroups(extractedCv, profile)
+	jobDescription, err := c.retrieveContext(ctx, job, "job_description", c.retrievalQueries(job.JobTitle, "job description", cvGroups), 5, latestVersionOptions()...)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
-	cvRubric, err := c.chroma.Query(ctx, "cv_rubric", job.JobTitle+" "+"cv rubric", 5)
+	cvRubric, err := c.retrieveContext(ctx, job, "cv_rubric", c.retrievalQueries(job.JobTitle, "cv rubric", cvGroups), 5, rubricOptions...)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
+	cvContext := c.fitContextBudget(job, "cv_context_budget",
+		contextSection{results: jobDescription, budget: c.promptBudget.Brief},
+		contextSection{results: cvRubric, budget: c.promptBudget.Rubric},
+	)
+	jobDescription, cvRubric = cvContext[0], cvContext[1]
 
-	cvEvaluatePrompt := c.buildCvEvaluatorPrompt(job.JobTitle, extractedCv, jobDescription, cvRubric)
-	cvGeminiResp, err := c.gemini.GenerateContent(ctx, job.JobTitle, cvEvaluatePrompt)
-	if err != nil {
-		c.jobFailToProcess(ctx, job, err)
-		return err
+	candidateName := ""
+	if profile != nil {
+		candidateName = profile.Name
 	}
-	cvResult := strings.Split(cvGeminiResp, "\n---\n")
-	if len(cvResult) < 2 {
-		err = fmt.Errorf("invalid response from gemini")
+	cvText := c.cvPromptText(extractedCv, profile)
+	cvInput := c.fitCandidateText(job, "cv_budget", c.blindCvInput(job, cvText, candidateName))
+	cvEvaluatePrompt := c.buildCvEvaluatorPrompt(job.JobTitle, cvInput, jobDescription, cvRubric)
+	c.tracePrompt(job, "cv_evaluation_prompt", cvEvaluatePrompt)
+	cvStageOptions := c.stageOptions(job, StageCvScoring, generateOptions)
+	cvOptions := append(slices.Clone(cvStageOptions), c.cacheKeyOption(job, StageCvScoring, cvInput, jobDescription, cvRubric))
+	job.CvMatchRate, job.CvFeedback, err = c.scoreWithSamples(ctx, job, StageCvScoring, cvEvaluatePrompt, cvOptions, cvScoreScale)
+	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
-	job.CvMatchRate = cvResult[0]
-	job.CvFeedback = cvResult[1]
 	fmt.Println("job with id " + job.JobId + " have done processed cv")
 
+	c.counterfactualCheck(ctx, job, cvText, candidateName, jobDescription, cvRubric, cvStageOptions)
+	job.CvFeedback = c.verifyFeedback(ctx, job, StageCvScoring, job.CvFeedback,
+		feedbackEvidence{document: cvInput, references: append(slices.Clone(jobDescription), cvRubric...)},
+		generateOptions, cvOptions,
+	)
+
 	// Evaluate Report
-	caseStudyBrief, err := c.chroma.Query(ctx, "case_study_brief", job.JobTitle+" "+"case study brief", 5)
+	reportGroups := reportQueryGroups(extractedReport)
+	caseStudyBrief, err := c.retrieveContext(ctx, job, "case_study_brief", c.retrievalQueries(job.JobTitle, "case study brief", reportGroups), 5, latestVersionOptions()...)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
 
-	reportRubric, err := c.chroma.Query(ctx, "project_report_rubric", job.JobTitle+" "+"project report rubric", 5)
+	reportRubric, err := c.retrieveContext(ctx, job, "project_report_rubric", c.retrievalQueries(job.JobTitle, "project report rubric", reportGroups), 5, rubricOptions...)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
+	reportContext := c.fitContextBudget(job, "report_context_budget",
+		contextSection{results: caseStudyBrief, budget: c.promptBudget.Brief},
+		contextSection{results: reportRubric, budget: c.promptBudget.Rubric},
+	)
+	caseStudyBrief, reportRubric = reportContext[0], reportContext[1]
 
-	reportEvaluatePrompt := c.buildReportEvaluatorPrompt(job.JobTitle, extractedReport, caseStudyBrief, reportRubric)
-	reportGeminiResp, err := c.gemini.GenerateContent(ctx, job.JobTitle, reportEvaluatePrompt)
+	reportOptions := append(c.stageOptions(job, StageReportScoring, generateOptions), c.cacheKeyOption(job, StageReportScoring, extractedReport, caseStudyBrief, reportRubric))
+	job.ProjectScore, job.ProjectFeedback, err = c.evaluateReport(ctx, job, extractedReport, caseStudyBrief, reportRubric, reportOptions)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
-	reportResult := strings.Split(reportGeminiResp, "\n---\n")
-	if len(reportResult) < 2 {
-		err = fmt.Errorf("invalid response from gemini")
-		c.jobFailToProcess(ctx, job, err)
-		return err
-	}
-	job.ProjectScore = reportResult[0]
-	job.ProjectFeedback = reportResult[1]
+	job.ProjectFeedback = c.verifyFeedback(ctx, job, StageReportScoring, job.ProjectFeedback,
+		feedbackEvidence{document: extractedReport, references: append(slices.Clone(caseStudyBrief), reportRubric...)},
+		generateOptions, reportOptions,
+	)
 	fmt.Println("job with id " + job.JobId + " have done processed report")
 
 	// final
 	finalPrompt := c.buildFinalPrompt(job.CvMatchRate, job.CvFeedback, job.ProjectScore, job.ProjectFeedback)
-	overall, err := c.gemini.GenerateContent(ctx, job.JobTitle, finalPrompt)
+	c.tracePrompt(job, "final_prompt", finalPrompt)
+	summaryOptions := append(c.stageOptions(job, StageSummary, generateOptions), c.cacheKeyOption(job, StageSummary, job.CvFeedback+job.ProjectFeedback))
+	overall, err := c.gemini.GenerateContent(ctx, job.JobTitle, finalPrompt, summaryOptions...)
 	if err != nil {
 		c.jobFailToProcess(ctx, job, err)
 		return err
 	}
 	job.OverallSummary = overall
+
+	// the stored feedback is local, placeholders the model repeated are put back
+	job.CvFeedback = vault.Restore(job.CvFeedback)
+	job.ProjectFeedback = vault.Restore(job.ProjectFeedback)
+	job.OverallSummary = vault.Restore(job.OverallSummary)
+
 	job.Status = models.StatusCompleted
 	c.cvEvaluator.UpdateJobByJobId(ctx, jobId, job)
 
@@ -146,7 +414,113 @@ func (w *cvEvaluatorConsumerService) jobFailToProcess(ctx context.Context, job *
 	_ = w.cvEvaluator.UpdateJobByJobId(ctx, job.JobId, job)
 }
 
-func (w *cvEvaluatorConsumerService) buildCvEvaluatorPrompt(jobTitle, extractedCv string, jobDescription, cvRubric []models.ChromaSearchResult) string {
+// traceEvent appends a stage to the job trace, prompts are recorded after redaction so
+// the trace never holds the original PII.
+func (w *cvEvaluatorConsumerService) traceEvent(job *dao.CvEvaluatorJob, stage, detail string) {
+	if len(detail) > traceDetailLimit {
+		detail = detail[:traceDetailLimit] + "..."
+	}
+	job.Trace = append(job.Trace, models.JobTraceEvent{
+		Stage:  stage,
+		Detail: detail,
+		At:     time.Now(),
+	})
+}
+
+// jobGenerateOptions apply to every model call made for the job, re-ranking included.
+func (w *cvEvaluatorConsumerService) jobGenerateOptions(job *dao.CvEvaluatorJob) []geminiclient.GenerateOption {
+	return []geminiclient.GenerateOption{
+		geminiclient.WithModel(job.Model),
+		geminiclient.WithUsageRecorder(w.usageRecorder(job)),
+		geminiclient.WithCacheBypass(job.NoCache),
+		geminiclient.WithCacheHitRecorder(w.cacheHitRecorder(job)),
+	}
+}
+
+// usageRecorder adds the usage of every call to the job totals and records the call.
+func (w *cvEvaluatorConsumerService) usageRecorder(job *dao.CvEvaluatorJob) geminiclient.UsageRecorder {
+	return func(ctx context.Context, usage geminiclient.Usage) {
+		cost := w.prices.Cost(usage)
+
+		w.usageMu.Lock()
+		job.Usage.Calls++
+		job.Usage.PromptTokens += usage.PromptTokens
+		job.Usage.OutputTokens += usage.OutputTokens
+		job.Usage.TotalTokens += usage.TotalTokens
+		job.Usage.CostUsd += cost
+		w.usageMu.Unlock()
+
+		if w.usageRepository == nil {
+			return
+		}
+		err := w.usageRepository.Record(ctx, &dao.LlmUsage{
+			JobId:        job.JobId,
+			JobTitle:     job.JobTitle,
+			Model:        usage.Model,
+			PromptTokens: usage.PromptTokens,
+			OutputTokens: usage.OutputTokens,
+			TotalTokens:  usage.TotalTokens,
+			CostUsd:      cost,
+		})
+		if err != nil {
+			log.Printf("failed to record llm usage for job %s: %s", job.JobId, err.Error())
+		}
+	}
+}
+
+func (w *cvEvaluatorConsumerService) storeVault(ctx context.Context, job *dao.CvEvaluatorJob, vault *piiredactor.Vault) {
+	counts := vault.Counts()
+	kinds := make([]string, 0, len(counts))
+	for kind, count := range counts {
+		kinds = append(kinds, fmt.Sprintf("%s=%d", kind, count))
+	}
+	slices.Sort(kinds)
+	w.traceEvent(job, "redaction", fmt.Sprintf("level %s, replaced %s", w.redactor.Level(), strings.Join(kinds, " ")))
+
+	if len(counts) == 0 || w.vaultStore == nil {
+		return
+	}
+	if err := w.vaultStore.Save(ctx, job.JobId, vault.Mapping()); err != nil {
+		log.Printf("failed to store pii vault for job %s: %s", job.JobId, err.Error())
+	}
+}
+
+// ocrSummary keeps which pages of each document were recognized by the OCR fallback.
+func (w *cvEvaluatorConsumerService) ocrSummary(documents map[string]*ingestdocument.ExtractionResult) map[string]models.OcrDocumentSummary {
+	summary := make(map[string]models.OcrDocumentSummary)
+	for field, document := range documents {
+		if len(document.OcrPages) == 0 {
+			continue
+		}
+
+		summary[field] = models.OcrDocumentSummary{
+			Engine:     document.OcrEngine,
+			Pages:      document.OcrPages,
+			Confidence: document.OcrConfidence,
+		}
+	}
+
+	if len(summary) == 0 {
+		return nil
+	}
+	return summary
+}
+
+// rubricQueryOptions pins rubric retrieval to the job rubric version when one is set,
+// otherwise only the latest ingested version is searched.
+func (w *cvEvaluatorConsumerService) rubricQueryOptions(job *dao.CvEvaluatorJob) []chromaclient.QueryOption {
+	if job.RubricVersion == "" {
+		return latestVersionOptions()
+	}
+	return []chromaclient.QueryOption{chromaclient.WithWhereEq(ingestdocument.MetadataVersion, job.RubricVersion)}
+}
+
+// latestVersionOptions skips chunks of superseded document versions kept for pinning.
+func latestVersionOptions() []chromaclient.QueryOption {
+	return []chromaclient.QueryOption{chromaclient.WithWhereNotEq(ingestdocument.MetadataLatest, "false")}
+}
+
+func (w *cvEvaluatorConsumerService) buildCvEvaluatorPrompt(jobTitle, candidateCv string, jobDescription, cvRubric []models.ChromaSearchResult) string {
 	prompt := "Evaluate this CV for role: " + jobTitle + "\n"
 	prompt += "Job Description: \n"
 	for _, desc := range jobDescription {
@@ -160,7 +534,7 @@ func (w *cvEvaluatorConsumerService) buildCvEvaluatorPrompt(jobTitle, extractedC
 		prompt += "\n"
 	}
 	prompt += "\n----\n"
-	prompt += "With Candidate CV: \n" + extractedCv
+	prompt += "With Candidate CV: \n" + candidateCv
 	prompt += "\n-----\n"
 	prompt += "Return as:\n<0.0-1.0 match rate>\n---\n<brief feedback with 2-3 sentences>\n"
 	return prompt
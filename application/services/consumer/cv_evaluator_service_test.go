@@ -0,0 +1,409 @@
+package service_consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/helper"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
+	"gorm.io/gorm"
+)
+
+// record sends the prompts to Gemini and rewrites the fixtures, run it after a prompt
+// changes: GEMINI_API_KEY=... go test ./application/services/consumer -record
+var record = flag.Bool("record", false, "record the gemini responses into testdata/fixtures")
+
+const (
+	testJobTitle        = "Backend Engineer"
+	testFileId          = "file-1"
+	testDocumentsDir    = "testdata/documents"
+	testFixturesDir     = "testdata/fixtures"
+	defaultTestModel    = "gemini-2.5-flash"
+	cvPromptPrefix      = "Evaluate this CV for role:"
+	reportPromptPrefix  = "Evaluate this Project report for role:"
+	summaryPromptPrefix = "Give 3-5 sentences summary"
+	profilePromptPrefix = "Extract the candidate profile"
+)
+
+var (
+	errModelUnavailable = errors.New("model unavailable")
+
+	knowledgeCollections = []string{"job_description", "cv_rubric", "case_study_brief", "project_report_rubric"}
+)
+
+// failingGemini answers the prompts starting with prefix itself, every other prompt goes
+// to the inner client.
+type failingGemini struct {
+	inner  geminiclient.IGeminiClient
+	prefix string
+	resp   string
+	err    error
+}
+
+func (f *failingGemini) GenerateContent(ctx context.Context, jobTitle, prompt string, opts ...geminiclient.GenerateOption) (string, error) {
+	if strings.HasPrefix(prompt, f.prefix) {
+		return f.resp, f.err
+	}
+	return f.inner.GenerateContent(ctx, jobTitle, prompt, opts...)
+}
+
+type testPipeline struct {
+	service  ICvEvaluatorConsumerService
+	jobs     repository.ICvEvaluatorJobRepository
+	profiles repository.ICandidateProfileRepository
+}
+
+type pipelineConfig struct {
+	collections []string
+	documents   map[string]string
+	wrap        func(geminiclient.IGeminiClient) geminiclient.IGeminiClient
+}
+
+func defaultPipelineConfig() pipelineConfig {
+	return pipelineConfig{
+		collections: knowledgeCollections,
+		documents:   map[string]string{"cv_file": "cv.md", "report_file": "report.md"},
+	}
+}
+
+// newTestPipeline builds the consumer on the in memory vector store and repositories with
+// the knowledge documents ingested and the candidate documents uploaded as testFileId.
+func newTestPipeline(t *testing.T, config pipelineConfig) *testPipeline {
+	t.Helper()
+	ctx := context.Background()
+
+	chroma := chromaclient.NewMemoryChromaClient()
+	ingest := ingestdocument.NewIngestFile(chroma)
+	for _, collection := range config.collections {
+		content, err := os.ReadFile(filepath.Join(testDocumentsDir, collection+".md"))
+		if err != nil {
+			t.Fatalf("read %s: %s", collection, err.Error())
+		}
+		if err := ingest.IngestToChroma(ctx, collection, collection, string(content), map[string]interface{}{}, ingestdocument.WithDefaultIngestOptions()); err != nil {
+			t.Fatalf("ingest %s: %s", collection, err.Error())
+		}
+	}
+
+	uploadDir := t.TempDir()
+	uploadDocuments(t, uploadDir, config.documents)
+
+	gemini := testGemini(t)
+	if config.wrap != nil {
+		gemini = config.wrap(gemini)
+	}
+
+	pipeline := &testPipeline{
+		jobs:     repository.NewMemoryCvEvaluatorJobRepository(),
+		profiles: repository.NewMemoryCandidateProfileRepository(),
+	}
+	pipeline.service = NewCvEvaluatorConsumerService(gemini, chroma, ingest, pipeline.jobs, pipeline.profiles, WithUploadBasePath(uploadDir))
+	return pipeline
+}
+
+// testGemini replays testdata/fixtures, with -record it calls Gemini and records them.
+func testGemini(t *testing.T) geminiclient.IGeminiClient {
+	t.Helper()
+	if !*record {
+		return geminiclient.NewReplayClient(testFixturesDir)
+	}
+
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		t.Fatal("GEMINI_API_KEY is required to record fixtures")
+	}
+	model := os.Getenv("GEMINI_MODEL")
+	if model == "" {
+		model = defaultTestModel
+	}
+	live, err := geminiclient.NewGeminiAiCLient(context.Background(), apiKey, model)
+	if err != nil {
+		t.Fatalf("gemini client: %s", err.Error())
+	}
+	recording, err := geminiclient.NewRecordingClient(live, testFixturesDir)
+	if err != nil {
+		t.Fatalf("recording client: %s", err.Error())
+	}
+	return recording
+}
+
+// uploadDocuments stores the testdata documents the way the upload endpoint does.
+func uploadDocuments(t *testing.T, uploadDir string, documents map[string]string) {
+	t.Helper()
+	folder := filepath.Join(uploadDir, testFileId)
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &models.UploadManifest{FileId: testFileId, Documents: make(map[string]models.DocumentValidation)}
+	for field, name := range documents {
+		content, err := os.ReadFile(filepath.Join(testDocumentsDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := field + filepath.Ext(name)
+		if err := os.WriteFile(filepath.Join(folder, filename), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		manifest.Documents[field] = models.DocumentValidation{Field: field, Filename: filename, OriginalName: name, Valid: true, Code: models.ValidationOk}
+	}
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, helper.UploadManifestFilename), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *testPipeline) createJob(t *testing.T, jobId string, edit func(*dao.CvEvaluatorJob)) {
+	t.Helper()
+	job := &dao.CvEvaluatorJob{
+		JobId:         jobId,
+		JobTitle:      testJobTitle,
+		FileId:        testFileId,
+		PromptVersion: models.DefaultPromptVersion,
+		Status:        models.StatusQueued,
+	}
+	if edit != nil {
+		edit(job)
+	}
+	if err := p.jobs.CreateJobItem(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *testPipeline) job(t *testing.T, jobId string) *dao.CvEvaluatorJob {
+	t.Helper()
+	job, err := p.jobs.GetByJobId(context.Background(), jobId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func hasTraceStage(job *dao.CvEvaluatorJob, stage string) bool {
+	for _, event := range job.Trace {
+		if event.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunningJobCompletes(t *testing.T) {
+	pipeline := newTestPipeline(t, defaultPipelineConfig())
+	pipeline.createJob(t, "job-ok", nil)
+
+	if err := pipeline.service.RunningJob(context.Background(), "job-ok"); err != nil {
+		t.Fatalf("RunningJob: %s", err.Error())
+	}
+
+	job := pipeline.job(t, "job-ok")
+	if job.Status != models.StatusCompleted {
+		t.Fatalf("status = %s, want %s", job.Status, models.StatusCompleted)
+	}
+	if rate, err := parseScore(job.CvMatchRate); err != nil || rate < cvScoreScale.min || rate > cvScoreScale.max {
+		t.Errorf("cv match rate %q is not a score within [0, 1]", job.CvMatchRate)
+	}
+	if score, err := parseScore(job.ProjectScore); err != nil || score < reportScoreScale.min || score > reportScoreScale.max {
+		t.Errorf("project score %q is not a score within [1, 5]", job.ProjectScore)
+	}
+	if job.CvFeedback == "" || job.ProjectFeedback == "" || job.OverallSummary == "" {
+		t.Errorf("feedback and summary must be set, got %q, %q, %q", job.CvFeedback, job.ProjectFeedback, job.OverallSummary)
+	}
+	for _, collection := range knowledgeCollections {
+		if !hasTraceStage(job, "retrieval_"+collection) {
+			t.Errorf("trace has no retrieval of %s", collection)
+		}
+	}
+	if _, ok := job.GenerationSettings[string(StageCvScoring)]; !ok {
+		t.Errorf("generation settings of %s are not recorded", StageCvScoring)
+	}
+
+	profile, err := pipeline.profiles.GetByJobId(context.Background(), "job-ok")
+	if err != nil {
+		t.Fatalf("candidate profile: %s", err.Error())
+	}
+	if profile.Name == "" {
+		t.Error("candidate profile has no name")
+	}
+}
+
+func TestRunningJobCandidateProfileFailureIsNotFatal(t *testing.T) {
+	config := defaultPipelineConfig()
+	config.wrap = func(inner geminiclient.IGeminiClient) geminiclient.IGeminiClient {
+		return &failingGemini{inner: inner, prefix: profilePromptPrefix, err: errModelUnavailable}
+	}
+	pipeline := newTestPipeline(t, config)
+	pipeline.createJob(t, "job-no-profile", nil)
+
+	if err := pipeline.service.RunningJob(context.Background(), "job-no-profile"); err != nil {
+		t.Fatalf("RunningJob: %s", err.Error())
+	}
+
+	job := pipeline.job(t, "job-no-profile")
+	if job.Status != models.StatusCompleted {
+		t.Fatalf("status = %s, want %s", job.Status, models.StatusCompleted)
+	}
+	if _, err := pipeline.profiles.GetByJobId(context.Background(), "job-no-profile"); err == nil {
+		t.Error("candidate profile stored although its stage failed")
+	}
+}
+
+func TestRunningJobNotFound(t *testing.T) {
+	pipeline := newTestPipeline(t, defaultPipelineConfig())
+
+	err := pipeline.service.RunningJob(context.Background(), "missing")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("err = %v, want %v", err, gorm.ErrRecordNotFound)
+	}
+}
+
+func TestRunningJobFails(t *testing.T) {
+	withoutCollection := func(name string) func(*pipelineConfig) {
+		return func(config *pipelineConfig) {
+			config.collections = nil
+			for _, collection := range knowledgeCollections {
+				if collection != name {
+					config.collections = append(config.collections, collection)
+				}
+			}
+		}
+	}
+	withoutDocument := func(field string) func(*pipelineConfig) {
+		return func(config *pipelineConfig) {
+			delete(config.documents, field)
+		}
+	}
+	answering := func(prefix, resp string, err error) func(*pipelineConfig) {
+		return func(config *pipelineConfig) {
+			config.wrap = func(inner geminiclient.IGeminiClient) geminiclient.IGeminiClient {
+				return &failingGemini{inner: inner, prefix: prefix, resp: resp, err: err}
+			}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  func(*pipelineConfig)
+		job     func(*dao.CvEvaluatorJob)
+		wantErr error
+	}{
+		{
+			name: "unknown prompt version",
+			job:  func(job *dao.CvEvaluatorJob) { job.PromptVersion = "v0" },
+		},
+		{
+			name:    "cv file missing",
+			config:  withoutDocument("cv_file"),
+			wantErr: ingestdocument.ErrReadDocumentFile,
+		},
+		{
+			name:    "report file missing",
+			config:  withoutDocument("report_file"),
+			wantErr: ingestdocument.ErrReadDocumentFile,
+		},
+		{
+			name:    "job description not ingested",
+			config:  withoutCollection("job_description"),
+			wantErr: chromaclient.ErrCollectionNotFound,
+		},
+		{
+			name:    "cv rubric not ingested",
+			config:  withoutCollection("cv_rubric"),
+			wantErr: chromaclient.ErrCollectionNotFound,
+		},
+		{
+			name:    "case study brief not ingested",
+			config:  withoutCollection("case_study_brief"),
+			wantErr: chromaclient.ErrCollectionNotFound,
+		},
+		{
+			name:    "project report rubric not ingested",
+			config:  withoutCollection("project_report_rubric"),
+			wantErr: chromaclient.ErrCollectionNotFound,
+		},
+		{
+			name:    "cv scoring model error",
+			config:  answering(cvPromptPrefix, "", errModelUnavailable),
+			wantErr: errModelUnavailable,
+		},
+		{
+			name:   "cv scoring invalid response",
+			config: answering(cvPromptPrefix, "the candidate looks fine", nil),
+		},
+		{
+			name:    "report scoring model error",
+			config:  answering(reportPromptPrefix, "", errModelUnavailable),
+			wantErr: errModelUnavailable,
+		},
+		{
+			name:   "report scoring invalid response",
+			config: answering(reportPromptPrefix, "4.0 with good structure", nil),
+		},
+		{
+			name:    "summary model error",
+			config:  answering(summaryPromptPrefix, "", errModelUnavailable),
+			wantErr: errModelUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultPipelineConfig()
+			if tt.config != nil {
+				tt.config(&config)
+			}
+			pipeline := newTestPipeline(t, config)
+			pipeline.createJob(t, "job-fail", tt.job)
+
+			err := pipeline.service.RunningJob(context.Background(), "job-fail")
+			if err == nil {
+				t.Fatal("RunningJob succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			job := pipeline.job(t, "job-fail")
+			if job.Status != models.StatusFailed {
+				t.Errorf("status = %s, want %s", job.Status, models.StatusFailed)
+			}
+			if job.OverallSummary != "" {
+				t.Errorf("failed job has a summary %q", job.OverallSummary)
+			}
+		})
+	}
+}
+
+func TestOcrSummary(t *testing.T) {
+	service := &cvEvaluatorConsumerService{}
+
+	summary := service.ocrSummary(map[string]*ingestdocument.ExtractionResult{
+		"cv":     {OcrPages: []int{2, 3}, OcrEngine: "stub", OcrConfidence: 81.5},
+		"report": {Text: "text layer only"},
+	})
+	if len(summary) != 1 {
+		t.Fatalf("summary = %v, want only the cv", summary)
+	}
+	cv := summary["cv"]
+	if cv.Engine != "stub" || len(cv.Pages) != 2 || cv.Pages[0] != 2 || cv.Confidence != 81.5 {
+		t.Errorf("cv summary = %+v", cv)
+	}
+
+	if summary := service.ocrSummary(map[string]*ingestdocument.ExtractionResult{"cv": {}}); summary != nil {
+		t.Errorf("summary = %v, want nil without OCR pages", summary)
+	}
+}
@@ -0,0 +1,248 @@
+package service_consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+type VerificationMode string
+
+const (
+	VerificationOff VerificationMode = "off"
+	// VerificationAnnotate keeps the feedback and appends the ungrounded claims
+	VerificationAnnotate VerificationMode = "annotate"
+	// VerificationRegenerate rewrites the feedback without the ungrounded claims, claims
+	// still ungrounded after the rewrite are annotated
+	VerificationRegenerate VerificationMode = "regenerate"
+)
+
+const (
+	verificationKept        = "kept"
+	verificationAnnotated   = "annotated"
+	verificationRegenerated = "regenerated"
+)
+
+var ErrInvalidVerification = errors.New("error invalid verification response from gemini")
+
+// feedbackEvidence is what a feedback may be grounded in: the candidate document as the
+// model saw it and the retrieved brief and rubric chunks.
+type feedbackEvidence struct {
+	document   string
+	references []models.ChromaSearchResult
+}
+
+// verifyFeedback asks the judge model whether each claim of feedback is supported by the
+// evidence and returns the feedback to keep. base are the job options the judge stage
+// settings are added to, rewriteOpts are the options of the scoring stage. It never fails
+// the job, a failed judge call keeps the feedback as it is.
+func (c *cvEvaluatorConsumerService) verifyFeedback(
+	ctx context.Context,
+	job *dao.CvEvaluatorJob,
+	stage PipelineStage,
+	feedback string,
+	evidence feedbackEvidence,
+	base, rewriteOpts []geminiclient.GenerateOption,
+) string {
+	if c.verificationMode != VerificationAnnotate && c.verificationMode != VerificationRegenerate {
+		return feedback
+	}
+	if strings.TrimSpace(feedback) == "" {
+		return feedback
+	}
+
+	traceStage := "verification_" + string(stage)
+	evidence.document = c.fitCandidateText(job, traceStage+"_budget", evidence.document)
+	judgeOpts := append(c.stageOptions(job, StageVerification, base), c.cacheKeyOption(job, StageVerification, evidence.document, evidence.references))
+	verification := models.FeedbackVerification{
+		Mode:   string(c.verificationMode),
+		Action: verificationKept,
+	}
+	defer func() {
+		if job.FeedbackVerification == nil {
+			job.FeedbackVerification = make(map[string]models.FeedbackVerification)
+		}
+		job.FeedbackVerification[string(stage)] = verification
+	}()
+
+	claims, err := c.judgeFeedback(ctx, job, traceStage, feedback, evidence, judgeOpts)
+	if err != nil {
+		verification.Error = err.Error()
+		c.traceEvent(job, traceStage, "failed, "+err.Error())
+		return feedback
+	}
+	verification.Claims = claims
+	verification.Ungrounded = len(ungroundedClaims(claims))
+	c.traceEvent(job, traceStage, fmt.Sprintf("%d claims, %d ungrounded", len(claims), verification.Ungrounded))
+	if verification.Ungrounded == 0 {
+		return feedback
+	}
+
+	if c.verificationMode == VerificationRegenerate {
+		regenerated, regeneratedClaims, err := c.regenerateFeedback(ctx, job, traceStage, feedback, claims, evidence, judgeOpts, rewriteOpts)
+		if err != nil {
+			c.traceEvent(job, traceStage+"_regenerate", "failed, "+err.Error())
+		} else {
+			feedback = regenerated
+			verification.Claims = regeneratedClaims
+			verification.Ungrounded = len(ungroundedClaims(regeneratedClaims))
+			verification.Action = verificationRegenerated
+			c.traceEvent(job, traceStage+"_regenerate", fmt.Sprintf("%d claims, %d ungrounded", len(regeneratedClaims), verification.Ungrounded))
+			if verification.Ungrounded == 0 {
+				return feedback
+			}
+		}
+	}
+
+	if verification.Action == verificationKept {
+		verification.Action = verificationAnnotated
+	}
+	return annotateFeedback(feedback, ungroundedClaims(verification.Claims))
+}
+
+func (c *cvEvaluatorConsumerService) judgeFeedback(
+	ctx context.Context,
+	job *dao.CvEvaluatorJob,
+	traceStage, feedback string,
+	evidence feedbackEvidence,
+	opts []geminiclient.GenerateOption,
+) ([]models.ClaimVerdict, error) {
+	prompt := c.buildVerificationPrompt(feedback, evidence)
+	c.tracePrompt(job, traceStage+"_prompt", prompt)
+
+	opts = append(append([]geminiclient.GenerateOption{}, opts...), geminiclient.WithResponseMIMEType("application/json"))
+	resp, err := c.gemini.GenerateContent(ctx, job.JobTitle, prompt, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClaimVerdicts(resp)
+}
+
+// regenerateFeedback rewrites the feedback without the ungrounded claims and judges the
+// rewrite again.
+func (c *cvEvaluatorConsumerService) regenerateFeedback(
+	ctx context.Context,
+	job *dao.CvEvaluatorJob,
+	traceStage, feedback string,
+	claims []models.ClaimVerdict,
+	evidence feedbackEvidence,
+	judgeOpts, rewriteOpts []geminiclient.GenerateOption,
+) (string, []models.ClaimVerdict, error) {
+	prompt := c.buildRegeneratePrompt(feedback, ungroundedClaims(claims), evidence)
+	c.tracePrompt(job, traceStage+"_regenerate_prompt", prompt)
+
+	resp, err := c.gemini.GenerateContent(ctx, job.JobTitle, prompt, rewriteOpts...)
+	if err != nil {
+		return "", nil, err
+	}
+	regenerated := strings.TrimSpace(resp)
+	if regenerated == "" {
+		return "", nil, ErrInvalidVerification
+	}
+
+	regeneratedClaims, err := c.judgeFeedback(ctx, job, traceStage+"_recheck", regenerated, evidence, judgeOpts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return regenerated, regeneratedClaims, nil
+}
+
+func (w *cvEvaluatorConsumerService) buildVerificationPrompt(feedback string, evidence feedbackEvidence) string {
+	prompt := "Check the feedback below against the candidate document and the reference material.\n"
+	prompt += "Split the feedback into its individual claims about the candidate.\n"
+	prompt += "A claim is grounded only when the candidate document or the reference material supports it, otherwise it is ungrounded.\n"
+	prompt += "\n-----\n"
+	prompt += "Reference material: \n"
+	for _, reference := range evidence.references {
+		prompt += reference.Text
+		prompt += "\n"
+	}
+	prompt += "\n-----\n"
+	prompt += "Candidate document: \n" + evidence.document
+	prompt += "\n-----\n"
+	prompt += "Feedback: \n" + feedback
+	prompt += "\n-----\n"
+	prompt += "Return only JSON as:\n"
+	prompt += `{"claims": [{"claim": "", "verdict": "grounded|ungrounded", "evidence": "<short quote or empty>"}]}` + "\n"
+	return prompt
+}
+
+func (w *cvEvaluatorConsumerService) buildRegeneratePrompt(feedback string, ungrounded []string, evidence feedbackEvidence) string {
+	prompt := "Rewrite the feedback below so it only states what the candidate document or the reference material supports.\n"
+	prompt += "These claims are not supported and must be removed or corrected:\n"
+	for _, claim := range ungrounded {
+		prompt += "- " + claim + "\n"
+	}
+	prompt += "\n-----\n"
+	prompt += "Reference material: \n"
+	for _, reference := range evidence.references {
+		prompt += reference.Text
+		prompt += "\n"
+	}
+	prompt += "\n-----\n"
+	prompt += "Candidate document: \n" + evidence.document
+	prompt += "\n-----\n"
+	prompt += "Feedback: \n" + feedback
+	prompt += "\n-----\n"
+	prompt += "Return as:\n<brief feedback with 2-3 sentences>\n"
+	return prompt
+}
+
+// parseClaimVerdicts accepts the JSON object with or without a markdown code fence, a
+// verdict other than grounded counts as ungrounded.
+func parseClaimVerdicts(resp string) ([]models.ClaimVerdict, error) {
+	resp = strings.TrimSpace(resp)
+	start := strings.Index(resp, "{")
+	end := strings.LastIndex(resp, "}")
+	if start < 0 || end < start {
+		return nil, ErrInvalidVerification
+	}
+
+	var parsed struct {
+		Claims []models.ClaimVerdict `json:"claims"`
+	}
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &parsed); err != nil {
+		return nil, ErrInvalidVerification
+	}
+
+	claims := make([]models.ClaimVerdict, 0, len(parsed.Claims))
+	for _, claim := range parsed.Claims {
+		claim.Claim = strings.TrimSpace(claim.Claim)
+		if claim.Claim == "" {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(claim.Verdict), models.ClaimGrounded) {
+			claim.Verdict = models.ClaimGrounded
+		} else {
+			claim.Verdict = models.ClaimUngrounded
+		}
+		claims = append(claims, claim)
+	}
+
+	return claims, nil
+}
+
+func ungroundedClaims(claims []models.ClaimVerdict) []string {
+	ungrounded := []string{}
+	for _, claim := range claims {
+		if claim.Verdict != models.ClaimGrounded {
+			ungrounded = append(ungrounded, claim.Claim)
+		}
+	}
+	return ungrounded
+}
+
+func annotateFeedback(feedback string, ungrounded []string) string {
+	if len(ungrounded) == 0 {
+		return feedback
+	}
+	return strings.TrimSpace(feedback) + "\n\nNot supported by the submitted documents: " + strings.Join(ungrounded, "; ")
+}
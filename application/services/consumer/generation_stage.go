@@ -0,0 +1,41 @@
+package service_consumer
+
+import (
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+type PipelineStage string
+
+const (
+	StageCandidateProfile PipelineStage = "candidate_profile"
+	// StageCvScoring also covers the counterfactual re-scoring
+	StageCvScoring PipelineStage = "cv_scoring"
+	// StageReportScoring also covers the report parts of the map-reduce mode
+	StageReportScoring PipelineStage = "report_scoring"
+	StageSummary       PipelineStage = "summary"
+	// StageVerification is the judge of the feedback verification, rewrites use the
+	// scoring stage of the feedback
+	StageVerification PipelineStage = "verification"
+)
+
+// stageOptions adds the generation settings of stage to base and records them on the job,
+// a stage without its own model uses the job model.
+func (w *cvEvaluatorConsumerService) stageOptions(job *dao.CvEvaluatorJob, stage PipelineStage, base []geminiclient.GenerateOption) []geminiclient.GenerateOption {
+	settings, ok := w.stageSettings[stage]
+	if !ok {
+		settings = geminiclient.DefaultGenerationSettings()
+	}
+	if settings.Model == "" {
+		settings.Model = job.Model
+	}
+
+	if job.GenerationSettings == nil {
+		job.GenerationSettings = make(map[string]models.GenerationSettings)
+	}
+	job.GenerationSettings[string(stage)] = settings
+
+	opts := append([]geminiclient.GenerateOption{}, base...)
+	return append(opts, geminiclient.WithGenerationSettings(settings))
+}
@@ -0,0 +1,188 @@
+package service_consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
+)
+
+// PromptBudget caps the tokens of each prompt section, Brief covers the job description
+// and the case study brief.
+type PromptBudget struct {
+	Brief     int
+	Rubric    int
+	Candidate int
+}
+
+const (
+	// reportPartOverlap caps the end of a report part carried into the next one
+	reportPartOverlap = 200
+)
+
+func DefaultPromptBudget() PromptBudget {
+	return PromptBudget{
+		Brief:     3000,
+		Rubric:    3000,
+		Candidate: 12000,
+	}
+}
+
+type contextSection struct {
+	results []models.ChromaSearchResult
+	budget  int
+}
+
+// fitContextBudget takes chunks from the sections in turn, best first, until the section
+// budget or the budget shared by the prompt is spent, so every collection keeps its most
+// relevant chunks.
+func (w *cvEvaluatorConsumerService) fitContextBudget(job *dao.CvEvaluatorJob, stage string, sections ...contextSection) [][]models.ChromaSearchResult {
+	fitted := make([][]models.ChromaSearchResult, len(sections))
+	sectionUsed := make([]int, len(sections))
+	full := make([]bool, len(sections))
+	used, total, kept := 0, 0, 0
+	for _, section := range sections {
+		total += len(section.results)
+	}
+
+	for rank := 0; ; rank++ {
+		progressed := false
+		for idx, section := range sections {
+			if full[idx] || rank >= len(section.results) {
+				continue
+			}
+			progressed = true
+
+			tokens := w.contextTokenizer.CountTokens(section.results[rank].Text)
+			if used+tokens > w.contextTokens || (section.budget > 0 && sectionUsed[idx]+tokens > section.budget) {
+				full[idx] = true
+				continue
+			}
+			used += tokens
+			sectionUsed[idx] += tokens
+			kept++
+			fitted[idx] = append(fitted[idx], section.results[rank])
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	w.traceEvent(job, stage, fmt.Sprintf("kept %d of %d chunks, %d of %d tokens", kept, total, used, w.contextTokens))
+	return fitted
+}
+
+// fitCandidateText cuts the candidate text to the candidate budget, the cut is traced so
+// it never happens silently.
+func (w *cvEvaluatorConsumerService) fitCandidateText(job *dao.CvEvaluatorJob, stage, text string) string {
+	tokens := w.contextTokenizer.CountTokens(text)
+	if tokens <= w.promptBudget.Candidate {
+		return text
+	}
+
+	fitted := truncateToTokens(text, w.promptBudget.Candidate, w.contextTokenizer)
+	w.traceEvent(job, stage, fmt.Sprintf("truncated from %d to %d tokens", tokens, w.contextTokenizer.CountTokens(fitted)))
+	return fitted
+}
+
+// truncateToTokens keeps the longest prefix of text, cut at a word boundary, that fits in
+// budget tokens.
+func truncateToTokens(text string, budget int, tokenizer ingestdocument.ITokenizer) string {
+	words := strings.Fields(text)
+	low, high := 0, len(words)
+	for low < high {
+		mid := (low + high + 1) / 2
+		if tokenizer.CountTokens(strings.Join(words[:mid], " ")) <= budget {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return strings.Join(words[:low], " ")
+}
+
+// tracePrompt keeps the prompt in the trace together with its token count.
+func (w *cvEvaluatorConsumerService) tracePrompt(job *dao.CvEvaluatorJob, stage, prompt string) {
+	w.traceEvent(job, stage, fmt.Sprintf("%d tokens\n%s", w.contextTokenizer.CountTokens(prompt), prompt))
+}
+
+// evaluateReport scores the report in one prompt when it fits the candidate budget,
+// longer reports are split in parts that are scored on their own (map) and then combined
+// into the final score and feedback (reduce).
+func (w *cvEvaluatorConsumerService) evaluateReport(ctx context.Context, job *dao.CvEvaluatorJob, extractedReport string, caseStudyBrief, reportRubric []models.ChromaSearchResult, opts []geminiclient.GenerateOption) (string, string, error) {
+	if w.contextTokenizer.CountTokens(extractedReport) <= w.promptBudget.Candidate {
+		prompt := w.buildReportEvaluatorPrompt(job.JobTitle, extractedReport, caseStudyBrief, reportRubric)
+		w.tracePrompt(job, "report_evaluation_prompt", prompt)
+		return w.scoreWithSamples(ctx, job, StageReportScoring, prompt, opts, reportScoreScale)
+	}
+
+	partTokens := w.promptBudget.Candidate / 2
+	parts := w.ingest.ChunkText(extractedReport, ingestdocument.ChunkingConfig{
+		Strategy:      ingestdocument.ChunkByMarkdown,
+		MaxTokens:     partTokens,
+		OverlapTokens: min(reportPartOverlap, partTokens/10),
+		Tokenizer:     w.contextTokenizer,
+	})
+	w.traceEvent(job, "report_map_reduce", fmt.Sprintf("report has %d tokens, split in %d parts", w.contextTokenizer.CountTokens(extractedReport), len(parts)))
+
+	notes := make([]string, 0, len(parts))
+	for idx, part := range parts {
+		prompt := w.buildReportPartPrompt(job.JobTitle, idx+1, len(parts), part, reportRubric)
+		w.tracePrompt(job, fmt.Sprintf("report_part_%d_prompt", idx+1), prompt)
+		resp, err := w.gemini.GenerateContent(ctx, job.JobTitle, prompt, opts...)
+		if err != nil {
+			return "", "", err
+		}
+
+		partResult := strings.Split(resp, "\n---\n")
+		if len(partResult) < 2 {
+			return "", "", fmt.Errorf("invalid response from gemini for report part %d", idx+1)
+		}
+		notes = append(notes, fmt.Sprintf("Part %d of %d (score %s): %s", idx+1, len(parts), strings.TrimSpace(partResult[0]), strings.TrimSpace(partResult[1])))
+	}
+
+	combined := w.fitCandidateText(job, "report_notes_budget", strings.Join(notes, "\n\n"))
+	prompt := w.buildReportReducePrompt(job.JobTitle, combined, caseStudyBrief, reportRubric)
+	w.tracePrompt(job, "report_evaluation_prompt", prompt)
+	return w.scoreWithSamples(ctx, job, StageReportScoring, prompt, opts, reportScoreScale)
+}
+
+func (w *cvEvaluatorConsumerService) buildReportPartPrompt(jobTitle string, part, total int, text string, reportRubric []models.ChromaSearchResult) string {
+	prompt := fmt.Sprintf("This is part %d of %d of a Project report for role: %s\n", part, total, jobTitle)
+	prompt += "Projec Report Rubric: \n"
+	for _, rubric := range reportRubric {
+		prompt += rubric.Text
+		prompt += "\n"
+	}
+	prompt += "\n----\n"
+	prompt += "Report part: \n" + text
+	prompt += "\n-----\n"
+	prompt += "Only judge what this part shows, other parts are evaluated separately.\n"
+	prompt += "Return as:\n<1.0-5.0 project score for this part>\n---\n<key evidence and gaps in 3-5 sentences>\n"
+	return prompt
+}
+
+func (w *cvEvaluatorConsumerService) buildReportReducePrompt(jobTitle, notes string, studyCase, reportRubric []models.ChromaSearchResult) string {
+	prompt := "Evaluate this Project report for role: " + jobTitle + "\n"
+	prompt += "Role study case: \n"
+	for _, desc := range studyCase {
+		prompt += desc.Text
+		prompt += "\n"
+	}
+	prompt += "\n-----\n"
+	prompt += "Projec Report Rubric: \n"
+	for _, rubric := range reportRubric {
+		prompt += rubric.Text
+		prompt += "\n"
+	}
+	prompt += "\n----\n"
+	prompt += "The report was too long for one evaluation, these are the notes of each part: \n" + notes
+	prompt += "\n-----\n"
+	prompt += "Combine the parts into one evaluation of the whole report.\n"
+	prompt += "Return as:\n<1.0-5.0 project score>\n---\n<brief feedback with 2-3 sentences>\n"
+	return prompt
+}
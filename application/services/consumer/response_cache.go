@@ -0,0 +1,40 @@
+package service_consumer
+
+import (
+	"context"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+// cacheKeyOption makes the calls of stage cacheable by the client response cache. The key
+// holds the prompt version, the retrieved chunk ids and the candidate text hash, the client
+// adds the model, the generation settings and the prompt hash so two calls only share a
+// response when their prompts are identical.
+func (w *cvEvaluatorConsumerService) cacheKeyOption(job *dao.CvEvaluatorJob, stage PipelineStage, candidateText string, chunks ...[]models.ChromaSearchResult) geminiclient.GenerateOption {
+	chunkIds := []string{}
+	for _, results := range chunks {
+		for _, result := range results {
+			chunkIds = append(chunkIds, result.Id)
+		}
+	}
+
+	return geminiclient.WithCacheKey(geminiclient.CacheKey{
+		Stage:         string(stage),
+		PromptVersion: job.PromptVersion,
+		ChunkIds:      chunkIds,
+		CandidateHash: geminiclient.HashText(candidateText),
+	})
+}
+
+// cacheHitRecorder counts the responses served from the cache and notes them in the trace.
+func (w *cvEvaluatorConsumerService) cacheHitRecorder(job *dao.CvEvaluatorJob) geminiclient.CacheHitRecorder {
+	return func(ctx context.Context, stage string) {
+		w.usageMu.Lock()
+		defer w.usageMu.Unlock()
+
+		job.Usage.CacheHits++
+		w.traceEvent(job, "cache_hit", stage)
+	}
+}
@@ -0,0 +1,198 @@
+package service_consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/retriever"
+)
+
+type RetrievalStrategy string
+
+const (
+	// RetrievalStrategyTitle queries every collection with the job title only
+	RetrievalStrategyTitle RetrievalStrategy = "title"
+	// RetrievalStrategyContent adds queries built from the skills and sections of the
+	// candidate documents
+	RetrievalStrategyContent RetrievalStrategy = "content"
+
+	// DefaultContextTokens is the budget shared by the retrieved chunks of one prompt
+	DefaultContextTokens = 6000
+	// maxQueryGroups caps the extra content queries sent per collection
+	maxQueryGroups  = 3
+	queryGroupTerms = 6
+)
+
+var (
+	placeholderPattern = regexp.MustCompile(`\[[A-Z]+(?:_\d+)?\]`)
+	markdownHeading    = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+)
+
+var queryStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"are": true, "was": true, "were": true, "have": true, "has": true, "had": true, "not": true,
+	"but": true, "you": true, "your": true, "our": true, "their": true, "they": true, "them": true,
+	"which": true, "will": true, "can": true, "into": true, "using": true, "used": true, "use": true,
+	"also": true, "more": true, "than": true, "each": true, "all": true, "any": true, "its": true,
+	"about": true, "over": true, "such": true, "other": true, "been": true, "being": true,
+	"experience": true, "years": true, "year": true, "work": true, "worked": true, "working": true,
+	"project": true, "report": true, "team": true, "responsible": true, "present": true,
+}
+
+// retrievalQueries always starts with the title query, the content strategy appends
+// one query per group of candidate terms.
+func (w *cvEvaluatorConsumerService) retrievalQueries(jobTitle, suffix string, groups []string) []string {
+	queries := []string{jobTitle + " " + suffix}
+	if w.retrievalStrategy != RetrievalStrategyContent {
+		return queries
+	}
+
+	for _, group := range groups {
+		queries = append(queries, jobTitle+" "+suffix+" "+group)
+	}
+	return queries
+}
+
+// cvQueryGroups prefers the parsed skills and recent roles, the CV key terms are used
+// when no profile is available.
+func cvQueryGroups(extractedCv string, profile *models.CandidateProfile) []string {
+	var groups []string
+	if profile != nil {
+		groups = append(groups, termGroups(profile.Skills)...)
+
+		var roles []string
+		for _, employment := range profile.Employment {
+			if employment.Title != "" {
+				roles = append(roles, employment.Title)
+			}
+		}
+		groups = append(groups, termGroups(roles)...)
+	}
+
+	if len(groups) == 0 {
+		groups = termGroups(keyTerms(extractedCv, queryGroupTerms*2))
+	}
+
+	if len(groups) > maxQueryGroups {
+		groups = groups[:maxQueryGroups]
+	}
+	return groups
+}
+
+// reportQueryGroups uses the report section headings followed by its key terms.
+func reportQueryGroups(extractedReport string) []string {
+	var headings []string
+	for _, match := range markdownHeading.FindAllStringSubmatch(extractedReport, -1) {
+		heading := strings.TrimSpace(placeholderPattern.ReplaceAllString(match[1], ""))
+		if heading != "" {
+			headings = append(headings, heading)
+		}
+	}
+
+	groups := termGroups(headings)
+	groups = append(groups, termGroups(keyTerms(extractedReport, queryGroupTerms))...)
+	if len(groups) > maxQueryGroups {
+		groups = groups[:maxQueryGroups]
+	}
+	return groups
+}
+
+func termGroups(terms []string) []string {
+	var groups []string
+	for start := 0; start < len(terms); start += queryGroupTerms {
+		end := min(start+queryGroupTerms, len(terms))
+		groups = append(groups, strings.Join(terms[start:end], " "))
+	}
+	return groups
+}
+
+// keyTerms returns the most frequent content words of text, PII placeholders and
+// stop words are skipped.
+func keyTerms(text string, limit int) []string {
+	text = placeholderPattern.ReplaceAllString(text, " ")
+	words := strings.FieldsFunc(strings.ToLower(text), func(ch rune) bool {
+		return !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '+' && ch != '#'
+	})
+
+	counts := make(map[string]int)
+	var order []string
+	for _, word := range words {
+		if len([]rune(word)) < 3 || queryStopWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		if counts[word] == 0 {
+			order = append(order, word)
+		}
+		counts[word]++
+	}
+
+	sort.SliceStable(order, func(a, b int) bool { return counts[order[a]] > counts[order[b]] })
+	if len(order) > limit {
+		order = order[:limit]
+	}
+	return order
+}
+
+// retrieveContext runs every query against the collection and keeps each chunk once with
+// its best score. A collection only fails when no query finds anything.
+func (w *cvEvaluatorConsumerService) retrieveContext(ctx context.Context, job *dao.CvEvaluatorJob, collectionName string, queries []string, topK int, opts ...chromaclient.QueryOption) ([]models.ChromaSearchResult, error) {
+	retrieveOptions := []retriever.RetrieveOption{
+		retriever.WithQueryOptions(opts...),
+		retriever.WithRerankCall(job.JobTitle, w.jobGenerateOptions(job)...),
+	}
+
+	merged := make(map[string]models.ChromaSearchResult)
+	var order []string
+	var firstErr error
+	for _, query := range queries {
+		results, err := w.retriever.Retrieve(ctx, collectionName, query, topK, retrieveOptions...)
+		if err != nil {
+			var notFound *chromaclient.ChromaNotFoundRecord
+			if !errors.As(err, &notFound) {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for _, result := range results {
+			existing, ok := merged[result.Id]
+			if !ok {
+				order = append(order, result.Id)
+			}
+			if !ok || result.Score > existing.Score {
+				merged[result.Id] = result
+			}
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil, firstErr
+	}
+
+	results := make([]models.ChromaSearchResult, 0, len(order))
+	for _, id := range order {
+		results = append(results, merged[id])
+	}
+	if len(queries) > 1 {
+		sort.SliceStable(results, func(a, b int) bool { return results[a].Score > results[b].Score })
+	}
+
+	picked := make([]string, 0, len(results))
+	for _, result := range results {
+		picked = append(picked, fmt.Sprintf("%s (%.3f)", result.Id, result.Score))
+	}
+	w.traceEvent(job, "retrieval_"+collectionName, "queries: "+strings.Join(queries, " | ")+"\npicked: "+strings.Join(picked, ", "))
+
+	return results, nil
+}
@@ -0,0 +1,172 @@
+package service_consumer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+// DefaultVarianceThreshold flags a stage whose scaled scores spread more than a standard
+// deviation of 0.1
+const DefaultVarianceThreshold = 0.01
+
+type scoreScale struct {
+	min float64
+	max float64
+}
+
+var (
+	cvScoreScale     = scoreScale{min: 0, max: 1}
+	reportScoreScale = scoreScale{min: 1, max: 5}
+)
+
+type scoreSample struct {
+	score    float64
+	raw      string
+	feedback string
+}
+
+// scoreWithSamples runs the scoring prompt once, or samples times when self-consistency is
+// enabled, and keeps the median score with the feedback of the sample closest to it. The
+// spread of the samples is stored on the job.
+func (w *cvEvaluatorConsumerService) scoreWithSamples(ctx context.Context, job *dao.CvEvaluatorJob, stage PipelineStage, prompt string, opts []geminiclient.GenerateOption, scale scoreScale) (string, string, error) {
+	if w.scoringSamples <= 1 {
+		resp, err := w.gemini.GenerateContent(ctx, job.JobTitle, prompt, opts...)
+		if err != nil {
+			return "", "", err
+		}
+		result := strings.Split(resp, "\n---\n")
+		if len(result) < 2 {
+			return "", "", fmt.Errorf("invalid response from gemini")
+		}
+		return result[0], result[1], nil
+	}
+
+	samples, err := w.collectSamples(ctx, job, prompt, opts, w.scoringSamples)
+	if err != nil {
+		return "", "", err
+	}
+
+	spread := w.scoreSpread(samples, scale)
+	if job.ScoreSpread == nil {
+		job.ScoreSpread = make(map[string]models.ScoreSpread)
+	}
+	job.ScoreSpread[string(stage)] = spread
+	if spread.LowConfidence {
+		job.LowConfidence = true
+	}
+	w.traceEvent(job, string(stage)+"_samples", fmt.Sprintf("%d of %d samples %v, median %.3f, variance %.4f", len(samples), w.scoringSamples, spread.Samples, spread.Median, spread.Variance))
+
+	closest := samples[0]
+	for _, sample := range samples[1:] {
+		if math.Abs(sample.score-spread.Median) < math.Abs(closest.score-spread.Median) {
+			closest = sample
+		}
+	}
+
+	// two decimals like a single sample, an averaged median would not fit the score columns
+	return strconv.FormatFloat(spread.Median, 'f', 2, 64), closest.feedback, nil
+}
+
+// medianScore samples the prompt as often as the job scores are sampled and returns the
+// median, the job spread is left alone so checks can score a variant prompt.
+func (w *cvEvaluatorConsumerService) medianScore(ctx context.Context, job *dao.CvEvaluatorJob, prompt string, opts []geminiclient.GenerateOption, scale scoreScale) (float64, error) {
+	samples, err := w.collectSamples(ctx, job, prompt, opts, max(w.scoringSamples, 1))
+	if err != nil {
+		return 0, err
+	}
+	return w.scoreSpread(samples, scale).Median, nil
+}
+
+// collectSamples runs the prompt count times in parallel and keeps the responses with a
+// parsable score.
+func (w *cvEvaluatorConsumerService) collectSamples(ctx context.Context, job *dao.CvEvaluatorJob, prompt string, opts []geminiclient.GenerateOption, count int) ([]scoreSample, error) {
+	responses := make([]string, count)
+	errs := make([]error, count)
+	var wg sync.WaitGroup
+	for idx := range responses {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			// every sample has its own cache entry, a cached run keeps its spread
+			sampleOpts := append(append([]geminiclient.GenerateOption{}, opts...), geminiclient.WithCacheVariant(fmt.Sprintf("sample_%d", idx)))
+			responses[idx], errs[idx] = w.gemini.GenerateContent(ctx, job.JobTitle, prompt, sampleOpts...)
+		}(idx)
+	}
+	wg.Wait()
+
+	var samples []scoreSample
+	var firstErr error
+	for idx, resp := range responses {
+		if errs[idx] != nil {
+			if firstErr == nil {
+				firstErr = errs[idx]
+			}
+			continue
+		}
+		result := strings.Split(resp, "\n---\n")
+		if len(result) < 2 {
+			continue
+		}
+		score, err := parseScore(result[0])
+		if err != nil {
+			continue
+		}
+		samples = append(samples, scoreSample{score: score, raw: result[0], feedback: result[1]})
+	}
+
+	if len(samples) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("invalid response from gemini")
+	}
+
+	return samples, nil
+}
+
+// scoreSpread also flags stages where less than half of the samples could be used.
+func (w *cvEvaluatorConsumerService) scoreSpread(samples []scoreSample, scale scoreScale) models.ScoreSpread {
+	scores := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		scores = append(scores, sample.score)
+	}
+	slices.Sort(scores)
+
+	median := scores[len(scores)/2]
+	if len(scores)%2 == 0 {
+		median = (scores[len(scores)/2-1] + scores[len(scores)/2]) / 2
+	}
+
+	width := scale.max - scale.min
+	mean := 0.0
+	for _, score := range scores {
+		mean += (score - scale.min) / width
+	}
+	mean /= float64(len(scores))
+
+	variance := 0.0
+	for _, score := range scores {
+		diff := (score-scale.min)/width - mean
+		variance += diff * diff
+	}
+	variance /= float64(len(scores))
+
+	return models.ScoreSpread{
+		Samples:       scores,
+		Median:        median,
+		Min:           scores[0],
+		Max:           scores[len(scores)-1],
+		Variance:      variance,
+		Threshold:     w.varianceThreshold,
+		LowConfidence: variance > w.varianceThreshold || len(scores)*2 < w.scoringSamples,
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	DefaultUploadMaxBytes = 10 << 20
+	DefaultUploadMaxPages = 30
+)
+
+type DocumentValidationConfig struct {
+	MaxBytes int64
+	MaxPages int
+	// AllowImageOnlyPdf accepts scanned PDFs when the consumer has an OCR fallback
+	AllowImageOnlyPdf bool
+}
+
+// DefaultDocumentValidationConfig is the upload limits used when none are configured.
+func DefaultDocumentValidationConfig() DocumentValidationConfig {
+	return DocumentValidationConfig{
+		MaxBytes: DefaultUploadMaxBytes,
+		MaxPages: DefaultUploadMaxPages,
+	}
+}
+
+func (c DocumentValidationConfig) sanitize() DocumentValidationConfig {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = DefaultUploadMaxBytes
+	}
+	if c.MaxPages <= 0 {
+		c.MaxPages = DefaultUploadMaxPages
+	}
+	return c
+}
+
+// validateDocument checks the uploaded bytes before they are written to disk so broken
+// documents are rejected at upload time instead of failing later in the consumer.
+func validateDocument(field, originalName string, data []byte, config DocumentValidationConfig) models.DocumentValidation {
+	config = config.sanitize()
+	result := models.DocumentValidation{
+		Field:       field,
+		ContentType: ingestdocument.SniffContentType(data, originalName),
+		SizeBytes:   int64(len(data)),
+		ValidatedAt: time.Now(),
+	}
+
+	if len(data) == 0 {
+		return rejectDocument(result, models.ValidationEmptyFile, "file is empty")
+	}
+
+	if int64(len(data)) > config.MaxBytes {
+		return rejectDocument(result, models.ValidationTooLarge, fmt.Sprintf("file exceeds maximum size of %d bytes", config.MaxBytes))
+	}
+
+	if !ingestdocument.IsSupportedContentType(result.ContentType) {
+		return rejectDocument(result, models.ValidationUnsupported, "file type is not supported, use PDF, DOCX, Markdown, HTML, RTF or plain text")
+	}
+
+	if result.ContentType == ingestdocument.ContentTypePdf {
+		return inspectPdf(result, data, config)
+	}
+
+	text, err := ingestdocument.ExtractTextFromBytes(result.ContentType, data)
+	if err != nil {
+		return rejectDocument(result, models.ValidationUnreadable, "document cannot be read")
+	}
+	if strings.TrimSpace(text) == "" {
+		return rejectDocument(result, models.ValidationNoText, "document has no extractable text")
+	}
+
+	return acceptDocument(result)
+}
+
+func inspectPdf(result models.DocumentValidation, data []byte, config DocumentValidationConfig) (validation models.DocumentValidation) {
+	// the pdf reader panics on some malformed documents
+	defer func() {
+		if rec := recover(); rec != nil {
+			validation = rejectDocument(result, models.ValidationUnreadable, "PDF document is malformed")
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		if err == pdf.ErrInvalidPassword {
+			return rejectDocument(result, models.ValidationEncrypted, "PDF document is encrypted")
+		}
+		return rejectDocument(result, models.ValidationUnreadable, "PDF document cannot be read")
+	}
+
+	if reader.Trailer().Key("Encrypt").Kind() != pdf.Null {
+		return rejectDocument(result, models.ValidationEncrypted, "PDF document is encrypted")
+	}
+
+	result.Pages = reader.NumPage()
+	if result.Pages == 0 {
+		return rejectDocument(result, models.ValidationUnreadable, "PDF document has no pages")
+	}
+	if result.Pages > config.MaxPages {
+		return rejectDocument(result, models.ValidationTooManyPages, fmt.Sprintf("PDF document exceeds maximum of %d pages", config.MaxPages))
+	}
+
+	if !hasExtractableText(reader) {
+		if config.AllowImageOnlyPdf {
+			result = acceptDocument(result)
+			result.Message = "PDF document has no extractable text, OCR is required"
+			return result
+		}
+		return rejectDocument(result, models.ValidationNoText, "PDF document has no extractable text")
+	}
+
+	return acceptDocument(result)
+}
+
+func hasExtractableText(reader *pdf.Reader) bool {
+	for page := 1; page <= reader.NumPage(); page++ {
+		p := reader.Page(page)
+		if p.V.IsNull() || p.V.Key("Contents").Kind() == pdf.Null {
+			continue
+		}
+
+		content, err := p.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+
+		if strings.TrimSpace(content) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func acceptDocument(result models.DocumentValidation) models.DocumentValidation {
+	result.Valid = true
+	result.Code = models.ValidationOk
+	result.Message = "valid"
+	return result
+}
+
+func rejectDocument(result models.DocumentValidation, code models.ValidationCode, message string) models.DocumentValidation {
+	result.Valid = false
+	result.Code = code
+	result.Message = message
+	return result
+}
@@ -5,12 +5,17 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/api"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/config"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -18,27 +23,45 @@ import (
 type IJobService interface {
 	EnqueueJob(context.Context, *models.EvaluateRequest) api.WebResponse
 	ResultJob(context.Context, string) api.WebResponse
+	RerunJob(context.Context, string, *models.RerunRequest) api.WebResponse
+	CompareJob(context.Context, string, string) api.WebResponse
+	CandidateProfile(context.Context, string) api.WebResponse
 }
 
 type jobService struct {
-	cvEvaluatorJobRepository repository.ICvEvaluatorJobRepository
-	kafkaProducer            IKafkaProducer
+	cvEvaluatorJobRepository   repository.ICvEvaluatorJobRepository
+	candidateProfileRepository repository.ICandidateProfileRepository
+	kafkaProducer              IKafkaProducer
+	chromaClient               chromaclient.IChromaClient
 }
 
-func NewEvaluateServce(cvEvaluatorJobRepository repository.ICvEvaluatorJobRepository, kafkaProducer IKafkaProducer) IJobService {
+// rubricCollections are the collections a rerun rubric version is pinned in
+var rubricCollections = []string{"cv_rubric", "project_report_rubric"}
+
+func NewEvaluateServce(
+	cvEvaluatorJobRepository repository.ICvEvaluatorJobRepository,
+	candidateProfileRepository repository.ICandidateProfileRepository,
+	kafkaProducer IKafkaProducer,
+	chromaClient chromaclient.IChromaClient,
+) IJobService {
 	return &jobService{
-		cvEvaluatorJobRepository: cvEvaluatorJobRepository,
-		kafkaProducer:            kafkaProducer,
+		cvEvaluatorJobRepository:   cvEvaluatorJobRepository,
+		candidateProfileRepository: candidateProfileRepository,
+		kafkaProducer:              kafkaProducer,
+		chromaClient:               chromaClient,
 	}
 }
 
 func (e *jobService) EnqueueJob(ctx context.Context, request *models.EvaluateRequest) api.WebResponse {
 	jobId := uuid.New().String()
 	jobItem := &dao.CvEvaluatorJob{
-		JobId:    jobId,
-		JobTitle: request.JobTitle,
-		FileId:   request.FileId,
-		Status:   models.StatusQueued,
+		JobId:         jobId,
+		JobTitle:      request.JobTitle,
+		FileId:        request.FileId,
+		Model:         config.Get().GeminiModel,
+		PromptVersion: models.DefaultPromptVersion,
+		Status:        models.StatusQueued,
+		NoCache:       request.NoCache,
 	}
 
 	if err := e.cvEvaluatorJobRepository.CreateJobItem(ctx, jobItem); err != nil {
@@ -68,11 +91,168 @@ func (e *jobService) ResultJob(ctx context.Context, jobId string) api.WebRespons
 		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
 	}
 
-	resp := &models.JobItem{
-		Id:       jobItem.JobId,
-		JobTitle: jobItem.JobTitle,
-		FileId:   jobItem.FileId,
-		Status:   jobItem.Status,
+	resp := toJobItem(jobItem)
+
+	return api.CreateWebResponse("Success", http.StatusOK, resp, nil)
+}
+
+func (e *jobService) RerunJob(ctx context.Context, jobId string, request *models.RerunRequest) api.WebResponse {
+	if request.PromptVersion != "" && !slices.Contains(models.PromptVersions, request.PromptVersion) {
+		log.Println("unknown prompt version")
+		return api.CreateWebResponse("Unknown prompt version", http.StatusBadRequest, nil, nil)
+	}
+	if request.Model != "" && !slices.Contains(rerunModels(), request.Model) {
+		log.Println("unknown model")
+		return api.CreateWebResponse("Unknown model", http.StatusBadRequest, nil, nil)
+	}
+	if request.RubricVersion != "" {
+		found, err := e.hasRubricVersion(ctx, request.RubricVersion)
+		if err != nil {
+			log.Println("error when get rubric version")
+			return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+		}
+		if !found {
+			log.Println("unknown rubric version")
+			return api.CreateWebResponse("Unknown rubric version", http.StatusBadRequest, nil, nil)
+		}
+	}
+
+	parentJob, err := e.cvEvaluatorJobRepository.GetByJobId(ctx, jobId)
+	if err != nil {
+		log.Println("error when get job")
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.CreateWebResponse("Job Not Found", http.StatusNotFound, nil, nil)
+		}
+
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	childJobId := uuid.New().String()
+	jobItem := &dao.CvEvaluatorJob{
+		JobId:         childJobId,
+		ParentJobId:   parentJob.JobId,
+		JobTitle:      firstNonEmpty(request.JobTitle, parentJob.JobTitle),
+		FileId:        parentJob.FileId,
+		Model:         firstNonEmpty(request.Model, parentJob.Model),
+		PromptVersion: firstNonEmpty(request.PromptVersion, parentJob.PromptVersion),
+		RubricVersion: firstNonEmpty(request.RubricVersion, parentJob.RubricVersion),
+		Status:        models.StatusQueued,
+		NoCache:       request.NoCache,
+	}
+
+	if err := e.cvEvaluatorJobRepository.CreateJobItem(ctx, jobItem); err != nil {
+		log.Println("failed to create rerun job")
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	go e.kafkaProducer.PublishMessage(ctx, config.Get().KafkaCvEvaluatorTopic, nil, childJobId)
+
+	resp := &models.EvaluateResponse{
+		JobId:  childJobId,
+		Status: string(jobItem.Status),
+	}
+
+	return api.CreateWebResponse("Success to enqueue the rerun job", http.StatusOK, resp, nil)
+}
+
+// rerunModels are the models a rerun may switch to, GEMINI_MODEL and GEMINI_MODELS.
+func rerunModels() []string {
+	return append([]string{config.Get().GeminiModel}, config.Get().GeminiModels...)
+}
+
+// hasRubricVersion reports whether every rubric collection holds chunks of version, a
+// rerun pinned to a missing version would retrieve no rubric and fail in the consumer.
+func (e *jobService) hasRubricVersion(ctx context.Context, version string) (bool, error) {
+	for _, collection := range rubricCollections {
+		documents, err := e.chromaClient.Get(ctx, collection, chromaclient.WithWhereEq(ingestdocument.MetadataVersion, version))
+		if err != nil {
+			return false, err
+		}
+		if len(documents) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e *jobService) CompareJob(ctx context.Context, jobId, otherJobId string) api.WebResponse {
+	jobItem, err := e.cvEvaluatorJobRepository.GetByJobId(ctx, jobId)
+	if err != nil {
+		log.Println("error when get job")
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.CreateWebResponse("Job Not Found", http.StatusNotFound, nil, nil)
+		}
+
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	otherJobItem, err := e.cvEvaluatorJobRepository.GetByJobId(ctx, otherJobId)
+	if err != nil {
+		log.Println("error when get other job")
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.CreateWebResponse("Other Job Not Found", http.StatusNotFound, nil, nil)
+		}
+
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	job := toJobItem(jobItem)
+	otherJob := toJobItem(otherJobItem)
+
+	resp := &models.CompareJobResponse{
+		Job:      *job,
+		OtherJob: *otherJob,
+		Diff:     diffJob(job, otherJob),
+	}
+
+	return api.CreateWebResponse("Success", http.StatusOK, resp, nil)
+}
+
+func (e *jobService) CandidateProfile(ctx context.Context, jobId string) api.WebResponse {
+	profile, err := e.candidateProfileRepository.GetByJobId(ctx, jobId)
+	if err != nil {
+		log.Println("error when get candidate profile")
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.CreateWebResponse("Candidate Profile Not Found", http.StatusNotFound, nil, nil)
+		}
+
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	resp := &models.CandidateProfile{
+		JobId:             profile.JobId,
+		FileId:            profile.FileId,
+		Name:              profile.Name,
+		Email:             profile.Email,
+		Phone:             profile.Phone,
+		Location:          profile.Location,
+		YearsOfExperience: profile.YearsOfExperience,
+		Employment:        profile.Employment,
+		Education:         profile.Education,
+		Skills:            profile.Skills,
+		Certifications:    profile.Certifications,
+		Links:             profile.Links,
+	}
+
+	return api.CreateWebResponse("Success", http.StatusOK, resp, nil)
+}
+
+func toJobItem(jobItem *dao.CvEvaluatorJob) *models.JobItem {
+	return &models.JobItem{
+		Id:            jobItem.JobId,
+		ParentJobId:   jobItem.ParentJobId,
+		JobTitle:      jobItem.JobTitle,
+		FileId:        jobItem.FileId,
+		Model:         jobItem.Model,
+		PromptVersion: jobItem.PromptVersion,
+		RubricVersion: jobItem.RubricVersion,
+		Status:        jobItem.Status,
+		LowConfidence: jobItem.LowConfidence,
+		NoCache:       jobItem.NoCache,
 		Result: models.JobResult{
 			CvMatchRate:     jobItem.CvMatchRate,
 			CvFeedback:      jobItem.CvFeedback,
@@ -80,7 +260,65 @@ func (e *jobService) ResultJob(ctx context.Context, jobId string) api.WebRespons
 			ProjectFeedback: jobItem.ProjectFeedback,
 			OverallSummary:  jobItem.OverallSummary,
 		},
+		Ocr:   jobItem.OcrSummary,
+		Trace: jobItem.Trace,
+		Bias:  jobItem.BiasCheck,
+		Usage: jobItem.Usage,
+
+		Generation:  jobItem.GenerationSettings,
+		Consistency: jobItem.ScoreSpread,
+
+		Verification: jobItem.FeedbackVerification,
 	}
+}
 
-	return api.CreateWebResponse("Success", http.StatusOK, resp, nil)
+// diffJob reports how otherJob differs from job; score deltas are otherJob minus job.
+func diffJob(job, otherJob *models.JobItem) models.JobDiff {
+	diff := models.JobDiff{
+		SameFile:               job.FileId == otherJob.FileId,
+		ChangedSettings:        []string{},
+		CvMatchRateDelta:       scoreDelta(job.Result.CvMatchRate, otherJob.Result.CvMatchRate),
+		ProjectScoreDelta:      scoreDelta(job.Result.ProjectScore, otherJob.Result.ProjectScore),
+		CvFeedbackChanged:      job.Result.CvFeedback != otherJob.Result.CvFeedback,
+		ProjectFeedbackChanged: job.Result.ProjectFeedback != otherJob.Result.ProjectFeedback,
+		OverallSummaryChanged:  job.Result.OverallSummary != otherJob.Result.OverallSummary,
+	}
+
+	if job.JobTitle != otherJob.JobTitle {
+		diff.ChangedSettings = append(diff.ChangedSettings, "job_title")
+	}
+	if job.Model != otherJob.Model {
+		diff.ChangedSettings = append(diff.ChangedSettings, "model")
+	}
+	if job.PromptVersion != otherJob.PromptVersion {
+		diff.ChangedSettings = append(diff.ChangedSettings, "prompt_version")
+	}
+	if job.RubricVersion != otherJob.RubricVersion {
+		diff.ChangedSettings = append(diff.ChangedSettings, "rubric_version")
+	}
+
+	return diff
+}
+
+func scoreDelta(score, otherScore string) *float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(score), 64)
+	if err != nil {
+		return nil
+	}
+	otherValue, err := strconv.ParseFloat(strings.TrimSpace(otherScore), 64)
+	if err != nil {
+		return nil
+	}
+
+	delta := otherValue - value
+	return &delta
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,7 +14,9 @@ import (
 	"path/filepath"
 
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/api"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/helper"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
 	"github.com/google/uuid"
 )
 
@@ -20,6 +24,7 @@ var (
 	ErrFileNotFound = errors.New("file not found")
 	ErrSaveFile     = errors.New("file error to save")
 	ErrDeleteFile   = errors.New("file failed to delete")
+	ErrReadFile     = errors.New("file error to read")
 )
 
 type IUploadDocumentService interface {
@@ -27,45 +32,87 @@ type IUploadDocumentService interface {
 }
 
 type uploadDocumentService struct {
-	basePath string
+	basePath         string
+	validationConfig DocumentValidationConfig
 }
 
-func NewUploadDocumentService(basePath string) IUploadDocumentService {
+func NewUploadDocumentService(basePath string, validationConfig DocumentValidationConfig) IUploadDocumentService {
 	return &uploadDocumentService{
-		basePath: basePath,
+		basePath:         basePath,
+		validationConfig: validationConfig.sanitize(),
 	}
 }
 
+type uploadedDocument struct {
+	field      string
+	filename   string
+	header     *multipart.FileHeader
+	data       []byte
+	validation models.DocumentValidation
+}
+
 func (u *uploadDocumentService) SaveUploadedDocument(ctx context.Context, req *models.UploadDocumentRequest) api.WebResponse {
 	folderId := uuid.New().String()
-	errChan := make(chan error, 2)
-	// save cv file
-
-	go func() {
-		fmt.Println("upload cv")
-		req.CvFileHeader.Filename = "cv_file.pdf"
-		if err := u.saveToPath(folderId, req.CvFile, req.CvFileHeader); err != nil {
-			errChan <- err
-			return
+
+	documents := []*uploadedDocument{
+		{field: "cv_file", header: req.CvFileHeader},
+		{field: "report_file", header: req.ReportFileHeader},
+	}
+	files := []multipart.File{req.CvFile, req.ReportFile}
+
+	// read and validate before anything touches the disk
+	var rejected []models.DocumentValidation
+	for idx, doc := range documents {
+		data, err := u.readLimited(files[idx])
+		if err != nil {
+			log.Println("error when read uploaded document")
+			return api.CreateWebResponse("Error when read user document", http.StatusBadRequest, nil, nil)
 		}
-		errChan <- nil
-	}()
-
-	go func() {
-		fmt.Println("upload report")
-		req.ReportFileHeader.Filename = "report_file.pdf"
-		if err := u.saveToPath(folderId, req.ReportFile, req.ReportFileHeader); err != nil {
-			errChan <- err
-			return
+
+		originalName := ""
+		if doc.header != nil {
+			originalName = doc.header.Filename
 		}
-		errChan <- nil
-	}()
 
+		doc.data = data
+		doc.validation = validateDocument(doc.field, originalName, data, u.validationConfig)
+		doc.filename = doc.field + ingestdocument.ExtensionForContentType(doc.validation.ContentType, originalName)
+		doc.validation.Filename = doc.filename
+		doc.validation.OriginalName = originalName
+
+		if !doc.validation.Valid {
+			rejected = append(rejected, doc.validation)
+		}
+	}
+
+	if len(rejected) > 0 {
+		log.Println("uploaded document failed validation")
+		return api.CreateWebResponse("Invalid document", http.StatusUnprocessableEntity, nil, rejected)
+	}
+
+	errChan := make(chan error, len(documents))
+	for _, doc := range documents {
+		go func(doc *uploadedDocument) {
+			fmt.Println("upload " + doc.field)
+			errChan <- u.saveToPath(folderId, doc.filename, bytes.NewReader(doc.data))
+		}(doc)
+	}
+
+	var saveErr error
+	for range documents {
+		if err := <-errChan; err != nil {
+			saveErr = err
+		}
+	}
 	fmt.Println("upload done")
 
-	err := <-errChan
-	if err != nil {
+	if saveErr == nil {
+		saveErr = u.saveManifest(folderId, documents)
+	}
+
+	if saveErr != nil {
 		log.Println("error when save document")
+		_ = os.RemoveAll(filepath.Join(u.basePath, folderId))
 		return api.CreateWebResponse("Error when save user document", http.StatusInternalServerError, nil, nil)
 	}
 
@@ -77,9 +124,39 @@ func (u *uploadDocumentService) SaveUploadedDocument(ctx context.Context, req *m
 
 }
 
-func (u *uploadDocumentService) saveToPath(folderId string, file multipart.File, header *multipart.FileHeader) error {
-	filename := header.Filename
+// readLimited reads at most one byte past the size limit so oversized files are
+// detected without buffering the whole upload.
+func (u *uploadDocumentService) readLimited(file multipart.File) ([]byte, error) {
+	if file == nil {
+		return nil, ErrFileNotFound
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, u.validationConfig.MaxBytes+1))
+	if err != nil {
+		return nil, ErrReadFile
+	}
+
+	return data, nil
+}
+
+func (u *uploadDocumentService) saveManifest(folderId string, documents []*uploadedDocument) error {
+	manifest := &models.UploadManifest{
+		FileId:    folderId,
+		Documents: make(map[string]models.DocumentValidation),
+	}
+	for _, doc := range documents {
+		manifest.Documents[doc.field] = doc.validation
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ErrSaveFile
+	}
+
+	return u.saveToPath(folderId, helper.UploadManifestFilename, bytes.NewReader(content))
+}
 
+func (u *uploadDocumentService) saveToPath(folderId, filename string, file io.Reader) error {
 	safeFilename := filepath.Clean(filename)
 	if safeFilename == "." || safeFilename == "/" {
 		safeFilename = filename
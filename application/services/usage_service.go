@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/api"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+)
+
+const (
+	usageDateLayout = "2006-01-02"
+	// defaultUsageDays is the report window when no from date is given
+	defaultUsageDays = 30
+)
+
+type IUsageService interface {
+	UsageReport(ctx context.Context, from, to string) api.WebResponse
+}
+
+type usageService struct {
+	llmUsageRepository repository.ILlmUsageRepository
+}
+
+func NewUsageService(llmUsageRepository repository.ILlmUsageRepository) IUsageService {
+	return &usageService{
+		llmUsageRepository: llmUsageRepository,
+	}
+}
+
+// UsageReport aggregates the model calls between from and to, both inclusive days.
+func (u *usageService) UsageReport(ctx context.Context, from, to string) api.WebResponse {
+	toDay := time.Now().UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		parsed, err := time.Parse(usageDateLayout, to)
+		if err != nil {
+			return api.CreateWebResponse("invalid to date, use YYYY-MM-DD", http.StatusBadRequest, nil, nil)
+		}
+		toDay = parsed
+	}
+
+	fromDay := toDay.AddDate(0, 0, -(defaultUsageDays - 1))
+	if from != "" {
+		parsed, err := time.Parse(usageDateLayout, from)
+		if err != nil {
+			return api.CreateWebResponse("invalid from date, use YYYY-MM-DD", http.StatusBadRequest, nil, nil)
+		}
+		fromDay = parsed
+	}
+
+	if fromDay.After(toDay) {
+		return api.CreateWebResponse("from date is after to date", http.StatusBadRequest, nil, nil)
+	}
+
+	rows, err := u.llmUsageRepository.Report(ctx, fromDay, toDay.AddDate(0, 0, 1))
+	if err != nil {
+		log.Println("error when get usage report")
+		return api.CreateWebResponse("internal server error", http.StatusInternalServerError, nil, nil)
+	}
+
+	resp := &models.UsageReport{
+		From: fromDay.Format(usageDateLayout),
+		To:   toDay.Format(usageDateLayout),
+		Rows: []models.UsageReportRow{},
+	}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, row)
+		resp.Total.Calls += row.Calls
+		resp.Total.PromptTokens += row.PromptTokens
+		resp.Total.OutputTokens += row.OutputTokens
+		resp.Total.TotalTokens += row.TotalTokens
+		resp.Total.CostUsd += row.CostUsd
+	}
+
+	return api.CreateWebResponse("Success", http.StatusOK, resp, nil)
+}
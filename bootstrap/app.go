@@ -2,18 +2,31 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/config"
 	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
 	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
 	gomysql "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/go-mysql"
+	gopostgres "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/go-postgres"
 	ingestdocument "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ingest-document"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/kafka"
+	llmcache "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/llm-cache"
+	ocrengine "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ocr-engine"
+	piiredactor "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/pii-redactor"
+	ratelimiter "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/rate-limiter"
 	"gorm.io/gorm"
 )
 
+// Database drivers, the values match the GORM dialector names
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+)
+
 type Application struct {
 	ENV           *config.Config
 	GeminiClient  geminiclient.IGeminiClient
@@ -21,48 +34,47 @@ type Application struct {
 	Ingest        ingestdocument.IIngestFile
 	DB            *gorm.DB
 	KafkaProducer *kafka.Producer
+	LlmBreaker    *ratelimiter.CircuitBreaker
 }
 
 func NewApp() *Application {
 	ctx := context.Background()
-	app := &Application{}
+	app := NewDatabaseApp()
+	db := app.DB
 
-	if err := config.Init(); err != nil {
-		log.Fatal("failed to initialize configuration")
+	// Init Gemini Client
+	app.LlmBreaker = ratelimiter.NewCircuitBreaker(app.ENV.GeminiCircuitFailures, time.Duration(app.ENV.GeminiCircuitOpenSeconds)*time.Second)
+	geminiOptions := []geminiclient.ClientOption{
+		geminiclient.WithCircuitBreaker(app.LlmBreaker),
+		geminiclient.WithRetryPolicy(geminiclient.RetryPolicy{MaxAttempts: app.ENV.GeminiMaxAttempts}),
 	}
-
-	app.ENV = config.Get()
-
-	// Init DB
-	dbConfig := &gomysql.MysqlConfig{
-		DBUser:     app.ENV.DBUser,
-		DBPassword: app.ENV.DBPassword,
-		DBHost:     app.ENV.DBHost,
-		DBPort:     app.ENV.DBPort,
-		DBName:     app.ENV.DBName,
-	}
-	db, err := gomysql.NewDatabaseConnection(dbConfig)
-	if err != nil {
-		log.Fatal("failed to create db connection")
+	if limiter := newLlmLimiter(app.ENV, db); limiter != nil {
+		geminiOptions = append(geminiOptions, geminiclient.WithRateLimiter(limiter))
 	}
-	app.DB = db
-
-	// Init Gemini Client
-	geminiCient, err := geminiclient.NewGeminiAiCLient(ctx, app.ENV.GeminiApiKey, app.ENV.GeminiModel)
+	if cache := newLlmCache(app.ENV, db); cache != nil {
+		geminiOptions = append(geminiOptions, geminiclient.WithResponseCache(cache, llmCacheTtl(app.ENV)))
+	}
+	geminiCient, err := geminiclient.NewGeminiAiCLient(ctx, app.ENV.GeminiApiKey, app.ENV.GeminiModel, geminiOptions...)
 	if err != nil {
 		log.Fatal("failed to init gemini client")
 	}
 	app.GeminiClient = geminiCient
 
 	// Init chroma
-	chromaClient, err := chromaclient.NewChromaClient(ctx, app.ENV.ChromaUrl)
+	chromaClient, err := newVectorStore(ctx, app.ENV, db)
 	if err != nil {
-		log.Fatalf("failed to init chroma client, %s", err.Error())
+		log.Fatalf("failed to init vector store, %s", err.Error())
 	}
 	app.ChromaClient = chromaClient
 
 	// Init ingestDocument
-	ingesDocument := ingestdocument.NewIngestFile(chromaClient)
+	ingestOptions := []ingestdocument.IngestFileOption{
+		ingestdocument.WithPdfExtractionMode(ingestdocument.PdfExtractionMode(app.ENV.PdfExtractionMode)),
+	}
+	if ocrEngine := newOcrEngine(app.ENV); ocrEngine != nil {
+		ingestOptions = append(ingestOptions, ingestdocument.WithOcrEngine(ocrEngine, app.ENV.OcrMinCharsPerPage))
+	}
+	ingesDocument := ingestdocument.NewIngestFile(chromaClient, ingestOptions...)
 	app.Ingest = ingesDocument
 
 	// Init Kafka Producer client
@@ -81,3 +93,147 @@ func NewApp() *Application {
 
 	return app
 }
+
+func newOcrEngine(env *config.Config) ocrengine.IOcrEngine {
+	switch env.OcrEngine {
+	case "", ocrengine.EngineNone:
+		return nil
+	case ocrengine.EngineTesseract:
+		engine, err := ocrengine.NewTesseractEngine(
+			ocrengine.WithTesseractPath(env.TesseractPath),
+			ocrengine.WithPdftoppmPath(env.PdftoppmPath),
+			ocrengine.WithLanguage(env.OcrLanguage),
+			ocrengine.WithDPI(env.OcrDpi),
+		)
+		if err != nil {
+			log.Printf("OCR engine failed to initialize, %s", err.Error())
+			return nil
+		}
+		return engine
+	default:
+		log.Printf("Unknown OCR engine: %s", env.OcrEngine)
+		return nil
+	}
+}
+
+// NewDatabaseApp only loads the configuration and opens the database, for commands that
+// do not evaluate anything.
+func NewDatabaseApp() *Application {
+	app := &Application{}
+
+	if err := config.Init(); err != nil {
+		log.Fatal("failed to initialize configuration")
+	}
+
+	app.ENV = config.Get()
+
+	// Init DB
+	db, err := newDatabase(app.ENV)
+	if err != nil {
+		log.Fatalf("failed to create db connection, %s", err.Error())
+	}
+	app.DB = db
+
+	return app
+}
+
+// newDatabase opens MySQL by default, the schema of both is managed by the migrate command.
+func newDatabase(env *config.Config) (*gorm.DB, error) {
+	switch env.DBDriver {
+	case "", DriverMysql:
+		return gomysql.NewDatabaseConnection(&gomysql.MysqlConfig{
+			DBUser:     env.DBUser,
+			DBPassword: env.DBPassword,
+			DBHost:     env.DBHost,
+			DBPort:     env.DBPort,
+			DBName:     env.DBName,
+		})
+	case DriverPostgres:
+		return gopostgres.NewDatabaseConnection(&gopostgres.PostgresConfig{
+			DBUser:     env.DBUser,
+			DBPassword: env.DBPassword,
+			DBHost:     env.DBHost,
+			DBPort:     env.DBPort,
+			DBName:     env.DBName,
+			SslMode:    env.DBSslMode,
+		})
+	default:
+		return nil, fmt.Errorf("unknown db driver %s", env.DBDriver)
+	}
+}
+
+// newVectorStore is the Chroma server by default, the embedded stores let the stack run
+// with only MySQL.
+func newVectorStore(ctx context.Context, env *config.Config, db *gorm.DB) (chromaclient.IChromaClient, error) {
+	switch env.VectorStore {
+	case "", chromaclient.StoreChroma:
+		return chromaclient.NewChromaClient(ctx, env.ChromaUrl)
+	case chromaclient.StoreMysql:
+		if db.Dialector.Name() != DriverMysql {
+			return nil, fmt.Errorf("vector store mysql needs DB_DRIVER=mysql, use pgvector on postgres")
+		}
+		return chromaclient.NewMysqlChromaClient(db), nil
+	case chromaclient.StorePgvector:
+		if db.Dialector.Name() != DriverPostgres {
+			return nil, fmt.Errorf("vector store pgvector needs DB_DRIVER=postgres")
+		}
+		return chromaclient.NewPgvectorChromaClient(ctx, db)
+	case chromaclient.StoreMemory:
+		path := env.VectorStoreSnapshot
+		if path == "" {
+			path = "./vector-store/snapshot.json"
+		}
+		return chromaclient.NewSnapshotChromaClient(path)
+	default:
+		return nil, fmt.Errorf("unknown vector store %s", env.VectorStore)
+	}
+}
+
+func newLlmLimiter(env *config.Config, db *gorm.DB) ratelimiter.ILimiter {
+	if env.GeminiRequestsPerMinute <= 0 && env.GeminiTokensPerMinute <= 0 {
+		return nil
+	}
+	if env.GeminiRateLimitShared {
+		return ratelimiter.NewSharedLimiter(db, "gemini", env.GeminiRequestsPerMinute, env.GeminiTokensPerMinute)
+	}
+	return ratelimiter.NewLocalLimiter(env.GeminiRequestsPerMinute, env.GeminiTokensPerMinute)
+}
+
+func newLlmCache(env *config.Config, db *gorm.DB) llmcache.ICacheStore {
+	switch env.LlmCache {
+	case "", llmcache.StoreOff:
+		return nil
+	case llmcache.StoreMysql:
+		return llmcache.NewMysqlStore(db)
+	case llmcache.StoreFile:
+		dir := env.LlmCacheDir
+		if dir == "" {
+			dir = "./llm-cache"
+		}
+		store, err := llmcache.NewFileStore(dir)
+		if err != nil {
+			log.Printf("LLM cache failed to initialize, %s", err.Error())
+			return nil
+		}
+		return store
+	default:
+		log.Printf("Unknown LLM cache: %s", env.LlmCache)
+		return nil
+	}
+}
+
+func llmCacheTtl(env *config.Config) time.Duration {
+	if env.LlmCacheTtlSeconds <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(env.LlmCacheTtlSeconds) * time.Second
+}
+
+// NewPiiVaultStore opens the encrypted PII mapping store under PII_VAULT_PATH.
+func NewPiiVaultStore(env *config.Config) (piiredactor.IVaultStore, error) {
+	vaultPath := env.PiiVaultPath
+	if vaultPath == "" {
+		vaultPath = "./pii-vault"
+	}
+	return piiredactor.NewEncryptedFileStore(vaultPath, env.PiiVaultKey)
+}
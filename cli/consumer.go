@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	service_consumer "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/consumer"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/handlers"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/kafka"
@@ -22,6 +23,8 @@ var consumerCommand = &cobra.Command{
 	Short: "Start consumer for Go CV Evaluator",
 	PreRun: func(cmd *cobra.Command, args []string) {
 		app := bootstrap.NewApp()
+		requireSchema(cmd.Context(), app)
+		backfillKnowledgeBase(cmd.Context(), app)
 		ctx := context.WithValue(cmd.Context(), appKey, app)
 		cmd.SetContext(ctx)
 	},
@@ -81,7 +84,22 @@ func createConsumer(app *bootstrap.Application, handler kafka.ConsumerController
 			return cfg
 		}()),
 		kafka.WithAckMode(kafka.AckModeAuto),
+		kafka.WithGate(app.LlmBreaker),
 	)
 
 	return consumer, err
 }
+
+// backfillKnowledgeBase marks chunks ingested before document versions as latest, the
+// evaluation would not find them otherwise.
+func backfillKnowledgeBase(ctx context.Context, app *bootstrap.Application) {
+	for _, collectionName := range service_consumer.KnowledgeCollections {
+		count, err := app.Ingest.BackfillLatest(ctx, collectionName)
+		if err != nil {
+			log.Fatalf("failed to backfill %s: %s", collectionName, err.Error())
+		}
+		if count > 0 {
+			log.Printf("marked %d chunks of %s as latest", count, collectionName)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	service_bench "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/bench"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/handlers"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	evalBenchCommand.Flags().String("dataset", "", "directory of labelled cases, one sub directory with a case.json per case")
+	evalBenchCommand.Flags().String("llm", service_bench.LlmGemini, "model client, gemini or replay")
+	evalBenchCommand.Flags().String("fixtures", "", "recorded responses directory, defaults to <dataset>/fixtures")
+	evalBenchCommand.Flags().Bool("record", false, "record the gemini responses into the fixtures directory")
+	evalBenchCommand.Flags().String("model", "", "job model, defaults to GEMINI_MODEL")
+	evalBenchCommand.Flags().Bool("no-cache", false, "skip the response cache")
+	evalBenchCommand.Flags().String("baseline", "", "report of a previous run to diff against")
+	evalBenchCommand.Flags().String("out", "", "write the report to this file instead of stdout")
+	rootCmd.AddCommand(evalBenchCommand)
+}
+
+var evalBenchCommand = &cobra.Command{
+	Use:   "eval-bench",
+	Short: "Score a golden dataset with the evaluation pipeline and report the agreement",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		app := bootstrap.NewApp()
+		ctx := context.WithValue(cmd.Context(), appKey, app)
+		cmd.SetContext(ctx)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		app := cmd.Context().Value(appKey).(*bootstrap.Application)
+		if err := runEvalBench(app, cmd); err != nil {
+			log.Fatalf("eval bench failed: %s", err.Error())
+		}
+	},
+}
+
+func runEvalBench(app *bootstrap.Application, cmd *cobra.Command) error {
+	dataset, _ := cmd.Flags().GetString("dataset")
+	if dataset == "" {
+		return fmt.Errorf("dataset is required. Use --dataset=<directory>")
+	}
+	llm, _ := cmd.Flags().GetString("llm")
+	fixtures, _ := cmd.Flags().GetString("fixtures")
+	if fixtures == "" {
+		fixtures = filepath.Join(dataset, "fixtures")
+	}
+	record, _ := cmd.Flags().GetBool("record")
+	model, _ := cmd.Flags().GetString("model")
+	if model == "" {
+		model = app.ENV.GeminiModel
+	}
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+
+	var gemini geminiclient.IGeminiClient
+	switch llm {
+	case service_bench.LlmGemini:
+		gemini = app.GeminiClient
+		if record {
+			recording, err := geminiclient.NewRecordingClient(app.GeminiClient, fixtures)
+			if err != nil {
+				return err
+			}
+			gemini = recording
+		}
+	case service_bench.LlmReplay:
+		gemini = geminiclient.NewReplayClient(fixtures)
+	default:
+		return fmt.Errorf("unknown llm %s", llm)
+	}
+
+	bench := handlers.NewEvalBench(app, gemini,
+		service_bench.WithBenchLlm(llm),
+		service_bench.WithBenchModel(model),
+		service_bench.WithBenchNoCache(noCache || record),
+	)
+	report, err := bench.Run(cmd.Context(), dataset)
+	if err != nil {
+		return err
+	}
+
+	if baselinePath, _ := cmd.Flags().GetString("baseline"); baselinePath != "" {
+		content, err := os.ReadFile(baselinePath)
+		if err != nil {
+			return err
+		}
+		var baseline models.BenchReport
+		if err := json.Unmarshal(content, &baseline); err != nil {
+			return fmt.Errorf("invalid baseline report: %s", err.Error())
+		}
+		report.Baseline = service_bench.DiffReports(&baseline, report)
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if out, _ := cmd.Flags().GetString("out"); out != "" {
+		return os.WriteFile(out, content, 0o644)
+	}
+	fmt.Println(string(content))
+	return nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	dbmigration "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/db-migration"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	migrateDownCommand.Flags().Int("steps", 1, "number of applied migrations to revert")
+	migrateCommand.AddCommand(migrateUpCommand, migrateDownCommand, migrateStatusCommand)
+	rootCmd.AddCommand(migrateCommand)
+}
+
+var migrateCommand = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the database schema",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		app := bootstrap.NewDatabaseApp()
+		ctx := context.WithValue(cmd.Context(), appKey, app)
+		cmd.SetContext(ctx)
+	},
+}
+
+var migrateUpCommand = &cobra.Command{
+	Use:   "up",
+	Short: "Apply every pending migration",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator := newMigrator(cmd)
+		applied, err := migrator.Up(cmd.Context())
+		for _, migration := range applied {
+			log.Printf("applied %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("migrate up failed: %s", err.Error())
+		}
+		if len(applied) == 0 {
+			log.Println("schema is up to date")
+		}
+	},
+}
+
+var migrateDownCommand = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the last applied migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps < 1 {
+			log.Fatal("steps must be at least 1")
+		}
+
+		migrator := newMigrator(cmd)
+		reverted, err := migrator.Down(cmd.Context(), steps)
+		for _, migration := range reverted {
+			log.Printf("reverted %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("migrate down failed: %s", err.Error())
+		}
+	},
+}
+
+var migrateStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and when they were applied",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator := newMigrator(cmd)
+		statuses, err := migrator.Status(cmd.Context())
+		if err != nil {
+			log.Fatalf("migrate status failed: %s", err.Error())
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+		}
+	},
+}
+
+func newMigrator(cmd *cobra.Command) dbmigration.IMigrator {
+	app := cmd.Context().Value(appKey).(*bootstrap.Application)
+	migrator, err := dbmigration.NewMigrator(app.DB)
+	if err != nil {
+		log.Fatalf("failed to load migrations: %s", err.Error())
+	}
+	return migrator
+}
+
+// requireSchema stops serve and consumer when a migration is pending, running against an
+// older schema would fail on the first query using a new column.
+func requireSchema(ctx context.Context, app *bootstrap.Application) {
+	migrator, err := dbmigration.NewMigrator(app.DB)
+	if err != nil {
+		log.Fatalf("failed to load migrations: %s", err.Error())
+	}
+	if err := migrator.CheckVersion(ctx); err != nil {
+		log.Fatalf("refusing to start: %s", err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	piiredactor "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/pii-redactor"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	piiVaultCommand.Flags().String("job", "", "job id whose PII mapping is read back")
+	rootCmd.AddCommand(piiVaultCommand)
+}
+
+var piiVaultCommand = &cobra.Command{
+	Use:   "pii-vault",
+	Short: "Print the PII mapping of a job and its trace with the placeholders restored",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		app := bootstrap.NewDatabaseApp()
+		ctx := context.WithValue(cmd.Context(), appKey, app)
+		cmd.SetContext(ctx)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		app := cmd.Context().Value(appKey).(*bootstrap.Application)
+		if err := runPiiVault(cmd.Context(), app, cmd); err != nil {
+			log.Fatalf("pii vault failed: %s", err.Error())
+		}
+	},
+}
+
+func runPiiVault(ctx context.Context, app *bootstrap.Application, cmd *cobra.Command) error {
+	jobId, _ := cmd.Flags().GetString("job")
+	if jobId == "" {
+		return fmt.Errorf("job is required. Use --job=<jobId>")
+	}
+
+	store, err := bootstrap.NewPiiVaultStore(app.ENV)
+	if err != nil {
+		return err
+	}
+	mapping, err := store.Load(ctx, jobId)
+	if errors.Is(err, piiredactor.ErrVaultNotFound) {
+		fmt.Printf("no PII mapping stored for job %s\n", jobId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	placeholders := make([]string, 0, len(mapping))
+	for placeholder := range mapping {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	for _, placeholder := range placeholders {
+		fmt.Printf("%-16s  %s\n", placeholder, mapping[placeholder])
+	}
+
+	job, err := repository.NewCvEvaluatorJobRepository(app).GetByJobId(ctx, jobId)
+	if err != nil {
+		return err
+	}
+	vault := piiredactor.NewVaultFromMapping(mapping)
+	for _, event := range job.Trace {
+		fmt.Printf("\n[%s] %s\n%s\n", event.At.UTC().Format("2006-01-02 15:04:05"), event.Stage, vault.Restore(event.Detail))
+	}
+
+	return nil
+}
@@ -34,6 +34,7 @@ var serveCommand = &cobra.Command{
 	Short: "Start The HTTP server Go Evaluator",
 	PreRun: func(cmd *cobra.Command, args []string) {
 		app := bootstrap.NewApp()
+		requireSchema(cmd.Context(), app)
 		ctx := context.WithValue(cmd.Context(), appKey, app)
 		cmd.SetContext(ctx)
 	},
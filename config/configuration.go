@@ -20,11 +20,14 @@ type Config struct {
 	GeminiApiKey               string   `mapstructure:"GEMINI_API_KEY"`
 	ChromaUrl                  string   `mapstructure:"CHROMA_URL"`
 	GeminiModel                string   `mapstructure:"GEMINI_MODEl"`
+	GeminiModels               []string `mapstructure:"GEMINI_MODELS"`
 	DBUser                     string   `mapstructure:"DB_USER"`
 	DBPassword                 string   `mapstructure:"DB_PASSWORD"`
 	DBHost                     string   `mapstructure:"DB_HOST"`
 	DBPort                     string   `mapstructure:"DB_PORT"`
 	DBName                     string   `mapstructure:"DB_NAME"`
+	DBDriver                   string   `mapstructure:"DB_DRIVER"`
+	DBSslMode                  string   `mapstructure:"DB_SSL_MODE"`
 	KafkaTLS                   bool     `mapstructure:"KAFKA_TLS"`
 	KafkaSASLEnable            bool     `mapstructure:"KAFKA_SASL_ENABLE"`
 	KafkaSASLHandshake         bool     `mapstructure:"KAFKA_SASL_HANDSHAKE"`
@@ -32,6 +35,53 @@ type Config struct {
 	KafkaMaxRetryPolicy        int      `mapstructure:"KAFKA_MAX_RETRY_POLICY"`
 	KafkaCvEvaluatorTopic      string   `mapstructure:"KAFKA_CV_EVALUATOR_TOPIC"`
 	KafkaCvEvaluatorTopicGroup string   `mapstructure:"KAFKA_CV_EVALUATOR_TOPIC_GROUP"`
+	UploadMaxBytes             int64    `mapstructure:"UPLOAD_MAX_BYTES"`
+	UploadMaxPages             int      `mapstructure:"UPLOAD_MAX_PAGES"`
+	PdfExtractionMode          string   `mapstructure:"PDF_EXTRACTION_MODE"`
+	OcrEngine                  string   `mapstructure:"OCR_ENGINE"`
+	OcrMinCharsPerPage         int      `mapstructure:"OCR_MIN_CHARS_PER_PAGE"`
+	OcrLanguage                string   `mapstructure:"OCR_LANGUAGE"`
+	OcrDpi                     int      `mapstructure:"OCR_DPI"`
+	TesseractPath              string   `mapstructure:"TESSERACT_PATH"`
+	PdftoppmPath               string   `mapstructure:"PDFTOPPM_PATH"`
+	CvPromptInput              string   `mapstructure:"CV_PROMPT_INPUT"`
+	PiiRedactionLevel          string   `mapstructure:"PII_REDACTION_LEVEL"`
+	PiiVaultPath               string   `mapstructure:"PII_VAULT_PATH"`
+	PiiVaultKey                string   `mapstructure:"PII_VAULT_KEY"`
+	BiasMode                   string   `mapstructure:"BIAS_MODE"`
+	BiasCounterfactualRate     float64  `mapstructure:"BIAS_COUNTERFACTUAL_RATE"`
+	BiasShiftThreshold         float64  `mapstructure:"BIAS_SHIFT_THRESHOLD"`
+	RetrievalMode              string   `mapstructure:"RETRIEVAL_MODE"`
+	RetrievalIndexRefresh      int      `mapstructure:"RETRIEVAL_INDEX_REFRESH_SECONDS"`
+	RetrievalReranker          string   `mapstructure:"RETRIEVAL_RERANKER"`
+	RerankerUrl                string   `mapstructure:"RERANKER_URL"`
+	RetrievalMinScores         string   `mapstructure:"RETRIEVAL_MIN_SCORES"`
+	RetrievalMultiplier        int      `mapstructure:"RETRIEVAL_CANDIDATE_MULTIPLIER"`
+	RetrievalStrategy          string   `mapstructure:"RETRIEVAL_STRATEGY"`
+	RetrievalContextTokens     int      `mapstructure:"RETRIEVAL_CONTEXT_TOKENS"`
+	PromptBudgetBrief          int      `mapstructure:"PROMPT_BUDGET_BRIEF"`
+	PromptBudgetRubric         int      `mapstructure:"PROMPT_BUDGET_RUBRIC"`
+	PromptBudgetCandidate      int      `mapstructure:"PROMPT_BUDGET_CANDIDATE"`
+	LlmPrices                  string   `mapstructure:"LLM_PRICES"`
+	GeminiRequestsPerMinute    int      `mapstructure:"GEMINI_REQUESTS_PER_MINUTE"`
+	GeminiTokensPerMinute      int      `mapstructure:"GEMINI_TOKENS_PER_MINUTE"`
+	GeminiRateLimitShared      bool     `mapstructure:"GEMINI_RATE_LIMIT_SHARED"`
+	GeminiMaxAttempts          int      `mapstructure:"GEMINI_MAX_ATTEMPTS"`
+	GeminiCircuitFailures      int      `mapstructure:"GEMINI_CIRCUIT_FAILURES"`
+	GeminiCircuitOpenSeconds   int      `mapstructure:"GEMINI_CIRCUIT_OPEN_SECONDS"`
+	GenerationCandidateProfile string   `mapstructure:"GENERATION_CANDIDATE_PROFILE"`
+	GenerationCvScoring        string   `mapstructure:"GENERATION_CV_SCORING"`
+	GenerationReportScoring    string   `mapstructure:"GENERATION_REPORT_SCORING"`
+	GenerationSummary          string   `mapstructure:"GENERATION_SUMMARY"`
+	GenerationVerification     string   `mapstructure:"GENERATION_VERIFICATION"`
+	ScoringSamples             int      `mapstructure:"SCORING_SAMPLES"`
+	ScoringVarianceThreshold   float64  `mapstructure:"SCORING_VARIANCE_THRESHOLD"`
+	FeedbackVerification       string   `mapstructure:"FEEDBACK_VERIFICATION"`
+	LlmCache                   string   `mapstructure:"LLM_CACHE"`
+	LlmCacheDir                string   `mapstructure:"LLM_CACHE_DIR"`
+	LlmCacheTtlSeconds         int      `mapstructure:"LLM_CACHE_TTL_SECONDS"`
+	VectorStore                string   `mapstructure:"VECTOR_STORE"`
+	VectorStoreSnapshot        string   `mapstructure:"VECTOR_STORE_SNAPSHOT"`
 }
 
 var appConfig Config
@@ -0,0 +1,14 @@
+package models
+
+type BiasCheck struct {
+	Mode              string   `json:"mode"`
+	BlindedAttributes []string `json:"blinded_attributes"`
+	// Sampled is true when the job was picked for the counterfactual re-score
+	Sampled             bool     `json:"sampled"`
+	Variant             string   `json:"variant,omitempty"`
+	OriginalScore       *float64 `json:"original_score,omitempty"`
+	CounterfactualScore *float64 `json:"counterfactual_score,omitempty"`
+	Shift               *float64 `json:"shift,omitempty"`
+	Threshold           float64  `json:"threshold"`
+	Flagged             bool     `json:"flagged"`
+}
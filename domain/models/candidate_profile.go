@@ -0,0 +1,32 @@
+package models
+
+type CandidateProfile struct {
+	JobId             string              `json:"job_id"`
+	FileId            string              `json:"file_id"`
+	Name              string              `json:"name"`
+	Email             string              `json:"email"`
+	Phone             string              `json:"phone"`
+	Location          string              `json:"location"`
+	YearsOfExperience float64             `json:"years_of_experience"`
+	Employment        []EmploymentHistory `json:"employment"`
+	Education         []EducationHistory  `json:"education"`
+	Skills            []string            `json:"skills"`
+	Certifications    []string            `json:"certifications"`
+	Links             []string            `json:"links"`
+}
+
+type EmploymentHistory struct {
+	Company   string `json:"company"`
+	Title     string `json:"title"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Summary   string `json:"summary"`
+}
+
+type EducationHistory struct {
+	Institution string `json:"institution"`
+	Degree      string `json:"degree"`
+	Field       string `json:"field"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+}
@@ -6,3 +6,9 @@ type ChromaUpsertRequest struct {
 	Content        string                 `json:"content"`
 	Metadata       map[string]interface{} `json:"metadata"`
 }
+
+type ChromaDocument struct {
+	Id       string                 `json:"id"`
+	Content  string                 `json:"content"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
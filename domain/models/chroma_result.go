@@ -3,4 +3,9 @@ package models
 type ChromaSearchResult struct {
 	Id   string
 	Text string
+	// Distance is the vector distance, lower is closer, zero for keyword-only matches
+	Distance float64
+	// Score is the relevance between 0 and 1 used for thresholds, higher is better
+	Score    float64
+	Metadata map[string]interface{}
 }
@@ -0,0 +1,27 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+type CandidateProfile struct {
+	Id                int                        `gorm:"column:id;primaryKey;autoIncrement"`
+	JobId             string                     `gorm:"column:job_id;type:varchar(50);uniqueIndex"`
+	FileId            string                     `gorm:"column:file_id;type:varchar(50)"`
+	Name              string                     `gorm:"column:name;type:varchar(255)"`
+	Email             string                     `gorm:"column:email;type:varchar(255)"`
+	Phone             string                     `gorm:"column:phone;type:varchar(50)"`
+	Location          string                     `gorm:"column:location;type:varchar(255)"`
+	YearsOfExperience float64                    `gorm:"column:years_of_experience"`
+	Employment        []models.EmploymentHistory `gorm:"column:employment;type:text;serializer:json"`
+	Education         []models.EducationHistory  `gorm:"column:education;type:text;serializer:json"`
+	Skills            []string                   `gorm:"column:skills;type:text;serializer:json"`
+	Certifications    []string                   `gorm:"column:certifications;type:text;serializer:json"`
+	Links             []string                   `gorm:"column:links;type:text;serializer:json"`
+	CreatedAt         time.Time                  `gorm:"column:created_at"`
+	UpdatedAt         time.Time                  `gorm:"column:updated_at"`
+}
+
+func (CandidateProfile) TableName() string { return "candidate_profile" }
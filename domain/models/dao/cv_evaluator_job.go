@@ -4,15 +4,32 @@ import "github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 
 type CvEvaluatorJob struct {
 	Id              int              `gorm:"column:id;primaryKey;autoIncrement"`
-	FileId          string           `gorm:"column:file_id;type:varchar(50)"`
-	JobId           string           `gorm:"column:job_id;type:varchar(50)"`
+	FileId          string           `gorm:"column:file_id;type:varchar(50);index"`
+	JobId           string           `gorm:"column:job_id;type:varchar(50);index"`
+	ParentJobId     string           `gorm:"column:parent_job_id;type:varchar(50)"`
 	JobTitle        string           `gorm:"column:job_title;type:text"`
-	Status          models.JobStatus `gorm:"column:status;type:enum('queued', 'processing', 'completed', 'failed')"`
+	Model           string           `gorm:"column:model;type:varchar(100)"`
+	PromptVersion   string           `gorm:"column:prompt_version;type:varchar(20)"`
+	RubricVersion   string           `gorm:"column:rubric_version;type:varchar(20)"`
+	Status          models.JobStatus `gorm:"column:status;type:varchar(20)"`
 	CvMatchRate     string           `gorm:"column:cv_match_rate;type:varchar(10)"`
 	CvFeedback      string           `gorm:"column:cv_feedback;type:text"`
 	ProjectScore    string           `gorm:"column:project_score;type:varchar(10)"`
 	ProjectFeedback string           `gorm:"column:project_feedback;type:text"`
 	OverallSummary  string           `gorm:"column:overall_summary;type:text"`
+	BiasFlagged     bool             `gorm:"column:bias_flagged;index"`
+	LowConfidence   bool             `gorm:"column:low_confidence;index"`
+	NoCache         bool             `gorm:"column:no_cache"`
+
+	OcrSummary map[string]models.OcrDocumentSummary `gorm:"column:ocr_summary;type:text;serializer:json"`
+	Trace      []models.JobTraceEvent               `gorm:"column:trace;type:longtext;serializer:json"`
+	BiasCheck  *models.BiasCheck                    `gorm:"column:bias_check;type:text;serializer:json"`
+	Usage      *models.JobUsage                     `gorm:"column:usage;type:text;serializer:json"`
+
+	GenerationSettings map[string]models.GenerationSettings `gorm:"column:generation_settings;type:text;serializer:json"`
+	ScoreSpread        map[string]models.ScoreSpread        `gorm:"column:score_spread;type:text;serializer:json"`
+
+	FeedbackVerification map[string]models.FeedbackVerification `gorm:"column:feedback_verification;type:text;serializer:json"`
 }
 
 func (CvEvaluatorJob) TableName() string { return "cv_evaluator_job" }
@@ -0,0 +1,12 @@
+package dao
+
+import "time"
+
+// LlmCacheEntry is one cached model response, the key hashes every input of the call
+type LlmCacheEntry struct {
+	CacheKey  string    `gorm:"column:cache_key;type:varchar(64);primaryKey"`
+	Response  string    `gorm:"column:response;type:longtext"`
+	ExpiresAt time.Time `gorm:"column:expires_at;index"`
+}
+
+func (LlmCacheEntry) TableName() string { return "llm_cache" }
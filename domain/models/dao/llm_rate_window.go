@@ -0,0 +1,13 @@
+package dao
+
+import "time"
+
+// LlmRateWindow counts the model calls of every process sharing a limiter key in one minute
+type LlmRateWindow struct {
+	LimiterKey  string    `gorm:"column:limiter_key;type:varchar(100);primaryKey"`
+	WindowStart time.Time `gorm:"column:window_start;primaryKey"`
+	Requests    int       `gorm:"column:requests"`
+	Tokens      int       `gorm:"column:tokens"`
+}
+
+func (LlmRateWindow) TableName() string { return "llm_rate_window" }
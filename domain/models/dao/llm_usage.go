@@ -0,0 +1,18 @@
+package dao
+
+import "time"
+
+// LlmUsage is one model call, rows are aggregated by the usage report
+type LlmUsage struct {
+	Id           int       `gorm:"column:id;primaryKey;autoIncrement"`
+	JobId        string    `gorm:"column:job_id;type:varchar(50);index"`
+	JobTitle     string    `gorm:"column:job_title;type:varchar(255)"`
+	Model        string    `gorm:"column:model;type:varchar(100);index"`
+	PromptTokens int       `gorm:"column:prompt_tokens"`
+	OutputTokens int       `gorm:"column:output_tokens"`
+	TotalTokens  int       `gorm:"column:total_tokens"`
+	CostUsd      float64   `gorm:"column:cost_usd"`
+	CreatedAt    time.Time `gorm:"column:created_at;index"`
+}
+
+func (LlmUsage) TableName() string { return "llm_usage" }
@@ -0,0 +1,15 @@
+package dao
+
+import "github.com/pgvector/pgvector-go"
+
+// PgVectorRecord is the vector_record table on PostgreSQL, the embedding is a pgvector
+// column searched by the database
+type PgVectorRecord struct {
+	CollectionName string                 `gorm:"column:collection_name;type:varchar(191);primaryKey"`
+	RecordId       string                 `gorm:"column:record_id;type:varchar(191);primaryKey"`
+	Content        string                 `gorm:"column:content;type:text"`
+	Metadata       map[string]interface{} `gorm:"column:metadata;type:jsonb;serializer:json"`
+	Embedding      pgvector.Vector        `gorm:"column:embedding;type:vector(384)"`
+}
+
+func (PgVectorRecord) TableName() string { return "vector_record" }
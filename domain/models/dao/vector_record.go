@@ -0,0 +1,13 @@
+package dao
+
+// VectorRecord is one document of an embedded vector collection, the embedding of its
+// content is stored as little endian float32 values
+type VectorRecord struct {
+	CollectionName string                 `gorm:"column:collection_name;type:varchar(191);primaryKey"`
+	RecordId       string                 `gorm:"column:record_id;type:varchar(191);primaryKey"`
+	Content        string                 `gorm:"column:content;type:longtext"`
+	Metadata       map[string]interface{} `gorm:"column:metadata;type:text;serializer:json"`
+	Embedding      []byte                 `gorm:"column:embedding;type:blob"`
+}
+
+func (VectorRecord) TableName() string { return "vector_record" }
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type ValidationCode string
+
+const (
+	ValidationOk           ValidationCode = "ok"
+	ValidationEmptyFile    ValidationCode = "empty_file"
+	ValidationUnsupported  ValidationCode = "unsupported_type"
+	ValidationTooLarge     ValidationCode = "file_too_large"
+	ValidationTooManyPages ValidationCode = "too_many_pages"
+	ValidationEncrypted    ValidationCode = "encrypted_pdf"
+	ValidationUnreadable   ValidationCode = "unreadable_document"
+	ValidationNoText       ValidationCode = "no_extractable_text"
+)
+
+type DocumentValidation struct {
+	Field        string         `json:"field"`
+	Filename     string         `json:"filename"`
+	OriginalName string         `json:"original_name"`
+	ContentType  string         `json:"content_type"`
+	SizeBytes    int64          `json:"size_bytes"`
+	Pages        int            `json:"pages"`
+	Valid        bool           `json:"valid"`
+	Code         ValidationCode `json:"code"`
+	Message      string         `json:"message"`
+	ValidatedAt  time.Time      `json:"validated_at"`
+}
+
+type UploadManifest struct {
+	FileId    string                        `json:"file_id"`
+	Documents map[string]DocumentValidation `json:"documents"`
+}
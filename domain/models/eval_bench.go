@@ -0,0 +1,74 @@
+package models
+
+const (
+	BenchMetricCvMatchRate  = "cv_match_rate"
+	BenchMetricProjectScore = "project_score"
+)
+
+// ScoreRange is the inclusive range a labelled score is expected in
+type ScoreRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// BenchCase is one labelled CV and report pair of a golden dataset, read from case.json
+type BenchCase struct {
+	Name     string                `json:"name"`
+	JobTitle string                `json:"job_title"`
+	Cv       string                `json:"cv"`
+	Report   string                `json:"report"`
+	Expected map[string]ScoreRange `json:"expected"`
+}
+
+type BenchCaseResult struct {
+	Name     string                `json:"name"`
+	Status   JobStatus             `json:"status"`
+	Error    string                `json:"error,omitempty"`
+	Scores   map[string]*float64   `json:"scores"`
+	Expected map[string]ScoreRange `json:"expected"`
+	InRange  map[string]bool       `json:"in_range"`
+}
+
+// BenchMetric compares the scores of one metric with the labels, the error of a score is
+// taken against the middle of its expected range
+type BenchMetric struct {
+	Cases  int `json:"cases"`
+	Scored int `json:"scored"`
+	// Failed are the cases without a readable score, they are not in the other numbers
+	Failed     int      `json:"failed"`
+	Mae        float64  `json:"mae"`
+	Spearman   *float64 `json:"spearman"`
+	OutOfRange int      `json:"out_of_range"`
+}
+
+type BenchReport struct {
+	Dataset  string                 `json:"dataset"`
+	Llm      string                 `json:"llm"`
+	RunAt    string                 `json:"run_at"`
+	Metrics  map[string]BenchMetric `json:"metrics"`
+	Cases    []BenchCaseResult      `json:"cases"`
+	Baseline *BenchDiff             `json:"baseline,omitempty"`
+}
+
+// BenchDiff is how a run differs from a previous one, deltas are current minus previous
+type BenchDiff struct {
+	RunAt   string                      `json:"run_at"`
+	Metrics map[string]BenchMetricDelta `json:"metrics"`
+	Cases   []BenchCaseDiff             `json:"cases"`
+}
+
+type BenchMetricDelta struct {
+	MaeDelta        float64  `json:"mae_delta"`
+	SpearmanDelta   *float64 `json:"spearman_delta"`
+	OutOfRangeDelta int      `json:"out_of_range_delta"`
+}
+
+// BenchCaseDiff is a case whose score or range check changed since the previous run
+type BenchCaseDiff struct {
+	Name            string   `json:"name"`
+	Metric          string   `json:"metric"`
+	Previous        *float64 `json:"previous"`
+	Current         *float64 `json:"current"`
+	PreviousInRange bool     `json:"previous_in_range"`
+	CurrentInRange  bool     `json:"current_in_range"`
+}
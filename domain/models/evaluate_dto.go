@@ -3,6 +3,8 @@ package models
 type EvaluateRequest struct {
 	JobTitle string `json:"job_title" validate:"required"`
 	FileId   string `json:"file_id" validate:"required"`
+	// NoCache skips the response cache for every model call of the job
+	NoCache bool `json:"no_cache"`
 }
 
 type EvaluateResponse struct {
@@ -0,0 +1,23 @@
+package models
+
+const (
+	ClaimGrounded   = "grounded"
+	ClaimUngrounded = "ungrounded"
+)
+
+// ClaimVerdict is the judge verdict on one claim of a generated feedback
+type ClaimVerdict struct {
+	Claim    string `json:"claim"`
+	Verdict  string `json:"verdict"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// FeedbackVerification is the verification pass of one feedback, Action tells whether the
+// feedback was kept, annotated or regenerated
+type FeedbackVerification struct {
+	Mode       string         `json:"mode"`
+	Claims     []ClaimVerdict `json:"claims"`
+	Ungrounded int            `json:"ungrounded"`
+	Action     string         `json:"action"`
+	Error      string         `json:"error,omitempty"`
+}
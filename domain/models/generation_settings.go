@@ -0,0 +1,10 @@
+package models
+
+// GenerationSettings are the effective model and sampling parameters of a pipeline stage
+type GenerationSettings struct {
+	Model           string  `json:"model"`
+	Temperature     float32 `json:"temperature"`
+	TopP            float32 `json:"top_p"`
+	TopK            float32 `json:"top_k"`
+	MaxOutputTokens int32   `json:"max_output_tokens"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type JobTraceEvent struct {
+	Stage  string    `json:"stage"`
+	Detail string    `json:"detail"`
+	At     time.Time `json:"at"`
+}
@@ -9,12 +9,35 @@ const (
 	StatusFailed     JobStatus = "failed"
 )
 
+const (
+	PromptVersionV1      = "v1"
+	DefaultPromptVersion = PromptVersionV1
+)
+
+var PromptVersions = []string{PromptVersionV1}
+
 type JobItem struct {
-	Id       string    `json:"id"`
-	JobTitle string    `json:"job_title"`
-	FileId   string    `json:"file_id"`
-	Status   JobStatus `json:"status"`
-	Result   JobResult `json:"result"`
+	Id            string    `json:"id"`
+	ParentJobId   string    `json:"parent_job_id,omitempty"`
+	JobTitle      string    `json:"job_title"`
+	FileId        string    `json:"file_id"`
+	Model         string    `json:"model,omitempty"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	RubricVersion string    `json:"rubric_version,omitempty"`
+	Status        JobStatus `json:"status"`
+	Result        JobResult `json:"result"`
+	LowConfidence bool      `json:"low_confidence"`
+	NoCache       bool      `json:"no_cache,omitempty"`
+
+	Ocr   map[string]OcrDocumentSummary `json:"ocr,omitempty"`
+	Trace []JobTraceEvent               `json:"trace,omitempty"`
+	Bias  *BiasCheck                    `json:"bias,omitempty"`
+	Usage *JobUsage                     `json:"usage,omitempty"`
+
+	Generation  map[string]GenerationSettings `json:"generation,omitempty"`
+	Consistency map[string]ScoreSpread        `json:"consistency,omitempty"`
+
+	Verification map[string]FeedbackVerification `json:"verification,omitempty"`
 }
 
 type JobResult struct {
@@ -0,0 +1,29 @@
+package models
+
+type JobUsage struct {
+	Calls        int     `json:"calls"`
+	PromptTokens int     `json:"prompt_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	CostUsd      float64 `json:"cost_usd"`
+	// CacheHits are the calls served from the response cache, they are not in the totals
+	CacheHits int `json:"cache_hits"`
+}
+
+type UsageReportRow struct {
+	Day          string  `json:"day"`
+	Model        string  `json:"model"`
+	JobTitle     string  `json:"job_title"`
+	Calls        int     `json:"calls"`
+	PromptTokens int     `json:"prompt_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	CostUsd      float64 `json:"cost_usd"`
+}
+
+type UsageReport struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Rows  []UsageReportRow `json:"rows"`
+	Total JobUsage         `json:"total"`
+}
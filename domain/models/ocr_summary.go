@@ -0,0 +1,7 @@
+package models
+
+type OcrDocumentSummary struct {
+	Engine     string  `json:"engine"`
+	Pages      []int   `json:"pages"`
+	Confidence float64 `json:"confidence"`
+}
@@ -0,0 +1,25 @@
+package models
+
+type RerunRequest struct {
+	JobTitle      string `json:"job_title"`
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version"`
+	RubricVersion string `json:"rubric_version"`
+	NoCache       bool   `json:"no_cache"`
+}
+
+type CompareJobResponse struct {
+	Job      JobItem `json:"job"`
+	OtherJob JobItem `json:"other_job"`
+	Diff     JobDiff `json:"diff"`
+}
+
+type JobDiff struct {
+	SameFile               bool     `json:"same_file"`
+	ChangedSettings        []string `json:"changed_settings"`
+	CvMatchRateDelta       *float64 `json:"cv_match_rate_delta"`
+	ProjectScoreDelta      *float64 `json:"project_score_delta"`
+	CvFeedbackChanged      bool     `json:"cv_feedback_changed"`
+	ProjectFeedbackChanged bool     `json:"project_feedback_changed"`
+	OverallSummaryChanged  bool     `json:"overall_summary_changed"`
+}
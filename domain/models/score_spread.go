@@ -0,0 +1,13 @@
+package models
+
+// ScoreSpread describes the independent scoring samples of one stage
+type ScoreSpread struct {
+	Samples []float64 `json:"samples"`
+	Median  float64   `json:"median"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	// Variance is computed on the scores scaled to 0..1, so stages are comparable
+	Variance      float64 `json:"variance"`
+	Threshold     float64 `json:"threshold"`
+	LowConfidence bool    `json:"low_confidence"`
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICandidateProfileRepository interface {
+	UpsertProfile(ctx context.Context, profile *dao.CandidateProfile) error
+	GetByJobId(ctx context.Context, jobId string) (*dao.CandidateProfile, error)
+}
+
+type candidateProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewCandidateProfileRepository(app *bootstrap.Application) ICandidateProfileRepository {
+	return &candidateProfileRepository{
+		db: app.DB,
+	}
+}
+
+func (c *candidateProfileRepository) UpsertProfile(ctx context.Context, profile *dao.CandidateProfile) error {
+	err := c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		UpdateAll: true,
+	}).Create(profile).Error
+	if err != nil {
+		log.Println("failed to upsert candidate profile")
+		return err
+	}
+	return nil
+}
+
+func (c *candidateProfileRepository) GetByJobId(ctx context.Context, jobId string) (*dao.CandidateProfile, error) {
+	var profile dao.CandidateProfile
+	if err := c.db.WithContext(ctx).Model(&dao.CandidateProfile{}).Where("job_id = ?", jobId).First(&profile).Error; err != nil {
+		log.Println("failed to get candidate profile by job id")
+		return nil, err
+	}
+
+	return &profile, nil
+}
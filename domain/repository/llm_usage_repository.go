@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+)
+
+type ILlmUsageRepository interface {
+	Record(ctx context.Context, usage *dao.LlmUsage) error
+	// Report aggregates the calls made in [from, to) by day, model and job title
+	Report(ctx context.Context, from, to time.Time) ([]models.UsageReportRow, error)
+}
+
+type llmUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewLlmUsageRepository(app *bootstrap.Application) ILlmUsageRepository {
+	return &llmUsageRepository{
+		db: app.DB,
+	}
+}
+
+func (l *llmUsageRepository) Record(ctx context.Context, usage *dao.LlmUsage) error {
+	if err := l.db.WithContext(ctx).Create(usage).Error; err != nil {
+		log.Println("failed to record llm usage")
+		return err
+	}
+	return nil
+}
+
+func (l *llmUsageRepository) Report(ctx context.Context, from, to time.Time) ([]models.UsageReportRow, error) {
+	day := "DATE_FORMAT(created_at, '%Y-%m-%d')"
+	if l.db.Dialector.Name() == bootstrap.DriverPostgres {
+		day = "TO_CHAR(created_at, 'YYYY-MM-DD')"
+	}
+
+	var rows []models.UsageReportRow
+	err := l.db.WithContext(ctx).Model(&dao.LlmUsage{}).
+		Select(day+" AS day, model, job_title, COUNT(*) AS calls, "+
+			"SUM(prompt_tokens) AS prompt_tokens, SUM(output_tokens) AS output_tokens, "+
+			"SUM(total_tokens) AS total_tokens, SUM(cost_usd) AS cost_usd").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("day, model, job_title").
+		Order("day, model, job_title").
+		Scan(&rows).Error
+	if err != nil {
+		log.Println("failed to aggregate llm usage")
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+)
+
+type memoryCvEvaluatorJobRepository struct {
+	jobs map[string]dao.CvEvaluatorJob
+	mu   sync.RWMutex
+}
+
+// NewMemoryCvEvaluatorJobRepository keeps the jobs in memory, for runs that must not
+// touch the job table such as the evaluation bench. A missing job is gorm.ErrRecordNotFound
+// like the database repository.
+func NewMemoryCvEvaluatorJobRepository() ICvEvaluatorJobRepository {
+	return &memoryCvEvaluatorJobRepository{jobs: make(map[string]dao.CvEvaluatorJob)}
+}
+
+func (m *memoryCvEvaluatorJobRepository) CreateJobItem(ctx context.Context, job *dao.CvEvaluatorJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.JobId] = *job
+	return nil
+}
+
+func (m *memoryCvEvaluatorJobRepository) GetByJobId(ctx context.Context, jobId string) (*dao.CvEvaluatorJob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[jobId]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &job, nil
+}
+
+func (m *memoryCvEvaluatorJobRepository) UpdateJobByJobId(ctx context.Context, jobId string, job *dao.CvEvaluatorJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[jobId] = *job
+	return nil
+}
+
+type memoryCandidateProfileRepository struct {
+	profiles map[string]dao.CandidateProfile
+	mu       sync.RWMutex
+}
+
+func NewMemoryCandidateProfileRepository() ICandidateProfileRepository {
+	return &memoryCandidateProfileRepository{profiles: make(map[string]dao.CandidateProfile)}
+}
+
+func (m *memoryCandidateProfileRepository) UpsertProfile(ctx context.Context, profile *dao.CandidateProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profiles[profile.JobId] = *profile
+	return nil
+}
+
+func (m *memoryCandidateProfileRepository) GetByJobId(ctx context.Context, jobId string) (*dao.CandidateProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	profile, ok := m.profiles[jobId]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	service_bench "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/bench"
+	service_consumer "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/consumer"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+// NewEvalBench runs the consumer pipeline configured from the environment over a golden
+// dataset, gemini is the model client under test.
+func NewEvalBench(app *bootstrap.Application, gemini geminiclient.IGeminiClient, opts ...service_bench.BenchOption) service_bench.IEvalBench {
+	factory := func(
+		jobs repository.ICvEvaluatorJobRepository,
+		profiles repository.ICandidateProfileRepository,
+		uploadBasePath string,
+	) service_consumer.ICvEvaluatorConsumerService {
+		return cvEvaluatorService(
+			app,
+			gemini,
+			jobs,
+			profiles,
+			service_consumer.WithUploadBasePath(uploadBasePath),
+			service_consumer.WithUsageTracking(nil, geminiclient.ParsePriceTable(app.ENV.LlmPrices)),
+		)
+	}
+
+	return service_bench.NewEvalBench(factory, opts...)
+}
@@ -5,12 +5,14 @@ import (
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	ocrengine "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ocr-engine"
 )
 
 type ServeController struct {
 	Hello          controllers.IHelloController
 	UploadDocument controllers.IUploadDocumentController
 	Evaluate       controllers.IJobController
+	Usage          controllers.IUsageController
 }
 
 func initDI(app *bootstrap.Application) *ServeController {
@@ -18,6 +20,7 @@ func initDI(app *bootstrap.Application) *ServeController {
 		Hello:          hello(app),
 		UploadDocument: uploadDocument(app),
 		Evaluate:       evaluate(app),
+		Usage:          usage(app),
 	}
 
 	return init
@@ -29,16 +32,33 @@ func hello(_ *bootstrap.Application) controllers.IHelloController {
 	return helloController
 }
 
-func uploadDocument(_ *bootstrap.Application) controllers.IUploadDocumentController {
-	uploadDocumentService := services.NewUploadDocumentService("./uploaded-file")
+func uploadDocument(app *bootstrap.Application) controllers.IUploadDocumentController {
+	validationConfig := services.DefaultDocumentValidationConfig()
+	if app.ENV.UploadMaxBytes > 0 {
+		validationConfig.MaxBytes = app.ENV.UploadMaxBytes
+	}
+	if app.ENV.UploadMaxPages > 0 {
+		validationConfig.MaxPages = app.ENV.UploadMaxPages
+	}
+	// scanned PDFs are recognized by the consumer OCR fallback
+	validationConfig.AllowImageOnlyPdf = app.ENV.OcrEngine != "" && app.ENV.OcrEngine != ocrengine.EngineNone
+	uploadDocumentService := services.NewUploadDocumentService("./uploaded-file", validationConfig)
 	uploadDocumentController := controllers.NewUploadDocumenController(uploadDocumentService)
 	return uploadDocumentController
 }
 
 func evaluate(app *bootstrap.Application) controllers.IJobController {
 	cvEvaluatorJobRepository := repository.NewCvEvaluatorJobRepository(app)
+	candidateProfileRepository := repository.NewCandidateProfileRepository(app)
 	kafkaProducer := services.NewKafkaProducer(app.KafkaProducer)
-	evaluateService := services.NewEvaluateServce(cvEvaluatorJobRepository, kafkaProducer)
+	evaluateService := services.NewEvaluateServce(cvEvaluatorJobRepository, candidateProfileRepository, kafkaProducer, app.ChromaClient)
 	evaluateController := controllers.NewEvaluateController(evaluateService)
 	return evaluateController
 }
+
+func usage(app *bootstrap.Application) controllers.IUsageController {
+	llmUsageRepository := repository.NewLlmUsageRepository(app)
+	usageService := services.NewUsageService(llmUsageRepository)
+	usageController := controllers.NewUsageController(usageService)
+	return usageController
+}
@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"log"
+	"time"
+
 	controller_consumer "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/controllers/consumer"
 	service_consumer "github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/services/consumer"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/repository"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+	piiredactor "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/pii-redactor"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/retriever"
 )
 
 type ConsumerController struct {
@@ -21,7 +28,118 @@ func initDIConsumer(app *bootstrap.Application) *ConsumerController {
 
 func cvEvaluatorConsumer(app *bootstrap.Application) controller_consumer.ICvEvaluatorControllerConsumer {
 	cvEvaluatorJobItem := repository.NewCvEvaluatorJobRepository(app)
-	cvEvaluatorServiceConsumer := service_consumer.NewCvEvaluatorConsumerService(app.GeminiClient, app.ChromaClient, app.Ingest, cvEvaluatorJobItem)
+	candidateProfile := repository.NewCandidateProfileRepository(app)
+	llmUsage := repository.NewLlmUsageRepository(app)
+	cvEvaluatorServiceConsumer := cvEvaluatorService(
+		app,
+		app.GeminiClient,
+		cvEvaluatorJobItem,
+		candidateProfile,
+		service_consumer.WithUsageTracking(llmUsage, geminiclient.ParsePriceTable(app.ENV.LlmPrices)),
+	)
 	cvEvaluatorControllerConsumer := controller_consumer.NewCvEvaluatorConsumer(cvEvaluatorServiceConsumer)
 	return cvEvaluatorControllerConsumer
 }
+
+// cvEvaluatorService is the evaluation pipeline configured from the environment, shared
+// by the consumer and the evaluation bench.
+func cvEvaluatorService(
+	app *bootstrap.Application,
+	gemini geminiclient.IGeminiClient,
+	cvEvaluatorJobItem repository.ICvEvaluatorJobRepository,
+	candidateProfile repository.ICandidateProfileRepository,
+	opts ...service_consumer.CvEvaluatorOption,
+) service_consumer.ICvEvaluatorConsumerService {
+	options := []service_consumer.CvEvaluatorOption{
+		service_consumer.WithCvPromptInput(service_consumer.CvPromptInput(app.ENV.CvPromptInput)),
+		service_consumer.WithPiiRedaction(piiRedaction(app)),
+		service_consumer.WithStageSettings(stageSettings(app)),
+		service_consumer.WithSelfConsistency(app.ENV.ScoringSamples, app.ENV.ScoringVarianceThreshold),
+		service_consumer.WithFeedbackVerification(service_consumer.VerificationMode(app.ENV.FeedbackVerification)),
+		service_consumer.WithRetriever(knowledgeRetriever(app, gemini)),
+		service_consumer.WithRetrievalStrategy(
+			service_consumer.RetrievalStrategy(app.ENV.RetrievalStrategy),
+			app.ENV.RetrievalContextTokens,
+		),
+		service_consumer.WithPromptBudget(service_consumer.PromptBudget{
+			Brief:     app.ENV.PromptBudgetBrief,
+			Rubric:    app.ENV.PromptBudgetRubric,
+			Candidate: app.ENV.PromptBudgetCandidate,
+		}),
+		service_consumer.WithBiasMitigation(
+			service_consumer.BiasMode(app.ENV.BiasMode),
+			app.ENV.BiasCounterfactualRate,
+			app.ENV.BiasShiftThreshold,
+		),
+	}
+
+	return service_consumer.NewCvEvaluatorConsumerService(
+		gemini,
+		app.ChromaClient,
+		app.Ingest,
+		cvEvaluatorJobItem,
+		candidateProfile,
+		append(options, opts...)...,
+	)
+}
+
+func piiRedaction(app *bootstrap.Application) (piiredactor.IRedactor, piiredactor.IVaultStore) {
+	redactor := piiredactor.NewRedactor(piiredactor.RedactionLevel(app.ENV.PiiRedactionLevel))
+	if redactor.Level() == piiredactor.LevelNone {
+		return redactor, nil
+	}
+
+	// the mapping is the only way back from the placeholders, redacting without keeping
+	// it is refused
+	store, err := bootstrap.NewPiiVaultStore(app.ENV)
+	if err != nil {
+		log.Fatalf("failed to open PII vault, set PII_VAULT_KEY or PII_REDACTION_LEVEL=none: %s", err.Error())
+	}
+
+	return redactor, store
+}
+
+func stageSettings(app *bootstrap.Application) map[service_consumer.PipelineStage]models.GenerationSettings {
+	configured := map[service_consumer.PipelineStage]string{
+		service_consumer.StageCandidateProfile: app.ENV.GenerationCandidateProfile,
+		service_consumer.StageCvScoring:        app.ENV.GenerationCvScoring,
+		service_consumer.StageReportScoring:    app.ENV.GenerationReportScoring,
+		service_consumer.StageSummary:          app.ENV.GenerationSummary,
+		service_consumer.StageVerification:     app.ENV.GenerationVerification,
+	}
+
+	settings := make(map[service_consumer.PipelineStage]models.GenerationSettings)
+	for stage, value := range configured {
+		parsed, err := geminiclient.ParseGenerationSettings(value, geminiclient.DefaultGenerationSettings())
+		if err != nil {
+			log.Printf("invalid generation settings for stage %s, using defaults: %s", stage, err.Error())
+		}
+		settings[stage] = parsed
+	}
+	return settings
+}
+
+func knowledgeRetriever(app *bootstrap.Application, gemini geminiclient.IGeminiClient) retriever.IRetriever {
+	opts := []retriever.RetrieverOption{
+		retriever.WithMinScores(retriever.ParseMinScores(app.ENV.RetrievalMinScores)),
+		retriever.WithCandidateMultiplier(app.ENV.RetrievalMultiplier),
+	}
+
+	if retriever.RetrievalMode(app.ENV.RetrievalMode) == retriever.ModeHybrid {
+		refresh := time.Duration(app.ENV.RetrievalIndexRefresh) * time.Second
+		opts = append(opts, retriever.WithHybrid(retriever.NewBM25Index(app.ChromaClient, refresh)))
+	}
+
+	switch app.ENV.RetrievalReranker {
+	case retriever.RerankerLlm:
+		opts = append(opts, retriever.WithReranker(retriever.NewLlmReranker(gemini)))
+	case retriever.RerankerCrossEncoder:
+		if app.ENV.RerankerUrl == "" {
+			log.Println("RERANKER_URL is empty, cross-encoder re-ranking is disabled")
+			break
+		}
+		opts = append(opts, retriever.WithReranker(retriever.NewCrossEncoderReranker(app.ENV.RerankerUrl)))
+	}
+
+	return retriever.NewRetriever(app.ChromaClient, opts...)
+}
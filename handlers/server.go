@@ -8,12 +8,14 @@ import (
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/api"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/application/controllers"
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/bootstrap"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/internal/generated"
 )
 
 type Server struct {
 	HelloController          controllers.IHelloController
 	UploadDocumentController controllers.IUploadDocumentController
 	EvaluateController       controllers.IJobController
+	UsageController          controllers.IUsageController
 }
 
 func NewServer(app *bootstrap.Application) (*Server, error) {
@@ -22,6 +24,7 @@ func NewServer(app *bootstrap.Application) (*Server, error) {
 		HelloController:          di.Hello,
 		UploadDocumentController: di.UploadDocument,
 		EvaluateController:       di.Evaluate,
+		UsageController:          di.Usage,
 	}
 
 	return server, nil
@@ -62,3 +65,47 @@ func (s *Server) GetResultJobId(w http.ResponseWriter, r *http.Request, jobId st
 	resp := s.EvaluateController.ResultJob(ctx, r, jobId)
 	api.WriteJSONResponse(w, resp.Status, resp)
 }
+
+func (s *Server) PostJobsJobIdRerun(w http.ResponseWriter, r *http.Request, jobId string) {
+	ctx := r.Context()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp := s.EvaluateController.RerunJob(ctx, r, jobId)
+	api.WriteJSONResponse(w, resp.Status, resp)
+}
+
+func (s *Server) GetJobsJobIdCompareOtherJobId(w http.ResponseWriter, r *http.Request, jobId string, otherJobId string) {
+	ctx := r.Context()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp := s.EvaluateController.CompareJob(ctx, r, jobId, otherJobId)
+	api.WriteJSONResponse(w, resp.Status, resp)
+}
+
+func (s *Server) GetJobsJobIdProfile(w http.ResponseWriter, r *http.Request, jobId string) {
+	ctx := r.Context()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp := s.EvaluateController.CandidateProfile(ctx, r, jobId)
+	api.WriteJSONResponse(w, resp.Status, resp)
+}
+
+func (s *Server) GetUsage(w http.ResponseWriter, r *http.Request, params generated.GetUsageParams) {
+	ctx := r.Context()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	from, to := "", ""
+	if params.From != nil {
+		from = *params.From
+	}
+	if params.To != nil {
+		to = *params.To
+	}
+
+	resp := s.UsageController.UsageReport(ctx, r, from, to)
+	api.WriteJSONResponse(w, resp.Status, resp)
+}
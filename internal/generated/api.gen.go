@@ -12,10 +12,62 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// CandidateProfileResponse defines model for CandidateProfileResponse.
+type CandidateProfileResponse struct {
+	Data *struct {
+		Certifications *[]string `json:"certifications,omitempty"`
+		Education      *[]struct {
+			Degree      *string `json:"degree,omitempty"`
+			EndDate     *string `json:"end_date,omitempty"`
+			Field       *string `json:"field,omitempty"`
+			Institution *string `json:"institution,omitempty"`
+			StartDate   *string `json:"start_date,omitempty"`
+		} `json:"education,omitempty"`
+		Email      *string `json:"email,omitempty"`
+		Employment *[]struct {
+			Company   *string `json:"company,omitempty"`
+			EndDate   *string `json:"end_date,omitempty"`
+			StartDate *string `json:"start_date,omitempty"`
+			Summary   *string `json:"summary,omitempty"`
+			Title     *string `json:"title,omitempty"`
+		} `json:"employment,omitempty"`
+		FileId            *string   `json:"file_id,omitempty"`
+		JobId             *string   `json:"job_id,omitempty"`
+		Links             *[]string `json:"links,omitempty"`
+		Location          *string   `json:"location,omitempty"`
+		Name              *string   `json:"name,omitempty"`
+		Phone             *string   `json:"phone,omitempty"`
+		Skills            *[]string `json:"skills,omitempty"`
+		YearsOfExperience *float32  `json:"years_of_experience,omitempty"`
+	} `json:"data,omitempty"`
+	Message *string `json:"message,omitempty"`
+	Status  *int    `json:"status,omitempty"`
+}
+
+// CompareResponse defines model for CompareResponse.
+type CompareResponse struct {
+	Data *struct {
+		Diff *struct {
+			ChangedSettings        *[]string `json:"changed_settings,omitempty"`
+			CvFeedbackChanged      *bool     `json:"cv_feedback_changed,omitempty"`
+			CvMatchRateDelta       *float32  `json:"cv_match_rate_delta,omitempty"`
+			OverallSummaryChanged  *bool     `json:"overall_summary_changed,omitempty"`
+			ProjectFeedbackChanged *bool     `json:"project_feedback_changed,omitempty"`
+			ProjectScoreDelta      *float32  `json:"project_score_delta,omitempty"`
+			SameFile               *bool     `json:"same_file,omitempty"`
+		} `json:"diff,omitempty"`
+		Job      *map[string]interface{} `json:"job,omitempty"`
+		OtherJob *map[string]interface{} `json:"other_job,omitempty"`
+	} `json:"data,omitempty"`
+	Message *string `json:"message,omitempty"`
+	Status  *int    `json:"status,omitempty"`
+}
+
 // EvaluateBodyRequest defines model for EvaluateBodyRequest.
 type EvaluateBodyRequest struct {
 	FileId   *string `json:"file_id,omitempty"`
 	JobTitle *string `json:"job_title,omitempty"`
+	NoCache  *bool   `json:"no_cache,omitempty"`
 }
 
 // EvaluateResponse defines model for EvaluateResponse.
@@ -27,20 +79,118 @@ type EvaluateResponse struct {
 	Status  *int    `json:"status,omitempty"`
 }
 
+// RerunBodyRequest defines model for RerunBodyRequest.
+type RerunBodyRequest struct {
+	JobTitle      *string `json:"job_title,omitempty"`
+	Model         *string `json:"model,omitempty"`
+	NoCache       *bool   `json:"no_cache,omitempty"`
+	PromptVersion *string `json:"prompt_version,omitempty"`
+	RubricVersion *string `json:"rubric_version,omitempty"`
+}
+
 // ResultResponse defines model for ResultResponse.
 type ResultResponse struct {
 	Data *struct {
-		FileId   *string `json:"file_id,omitempty"`
-		Id       *string `json:"id,omitempty"`
-		JobTitle *string `json:"job_title,omitempty"`
-		Result   *struct {
+		Bias *struct {
+			BlindedAttributes   *[]string `json:"blinded_attributes,omitempty"`
+			CounterfactualScore *float32  `json:"counterfactual_score,omitempty"`
+			Flagged             *bool     `json:"flagged,omitempty"`
+			Mode                *string   `json:"mode,omitempty"`
+			OriginalScore       *float32  `json:"original_score,omitempty"`
+			Sampled             *bool     `json:"sampled,omitempty"`
+			Shift               *float32  `json:"shift,omitempty"`
+			Threshold           *float32  `json:"threshold,omitempty"`
+			Variant             *string   `json:"variant,omitempty"`
+		} `json:"bias,omitempty"`
+		Consistency *map[string]struct {
+			LowConfidence *bool      `json:"low_confidence,omitempty"`
+			Max           *float32   `json:"max,omitempty"`
+			Median        *float32   `json:"median,omitempty"`
+			Min           *float32   `json:"min,omitempty"`
+			Samples       *[]float32 `json:"samples,omitempty"`
+			Threshold     *float32   `json:"threshold,omitempty"`
+			Variance      *float32   `json:"variance,omitempty"`
+		} `json:"consistency,omitempty"`
+		FileId     *string `json:"file_id,omitempty"`
+		Generation *map[string]struct {
+			MaxOutputTokens *int     `json:"max_output_tokens,omitempty"`
+			Model           *string  `json:"model,omitempty"`
+			Temperature     *float32 `json:"temperature,omitempty"`
+			TopK            *float32 `json:"top_k,omitempty"`
+			TopP            *float32 `json:"top_p,omitempty"`
+		} `json:"generation,omitempty"`
+		Id            *string `json:"id,omitempty"`
+		JobTitle      *string `json:"job_title,omitempty"`
+		LowConfidence *bool   `json:"low_confidence,omitempty"`
+		Model         *string `json:"model,omitempty"`
+		NoCache       *bool   `json:"no_cache,omitempty"`
+		Ocr           *map[string]struct {
+			Confidence *float32 `json:"confidence,omitempty"`
+			Engine     *string  `json:"engine,omitempty"`
+			Pages      *[]int   `json:"pages,omitempty"`
+		} `json:"ocr,omitempty"`
+		ParentJobId   *string `json:"parent_job_id,omitempty"`
+		PromptVersion *string `json:"prompt_version,omitempty"`
+		Result        *struct {
 			CvFeedback      *string `json:"cv_feedback,omitempty"`
 			CvMatchRate     *string `json:"cv_match_rate,omitempty"`
 			OverallSummary  *string `json:"overall_summary,omitempty"`
 			ProjectFeedback *string `json:"project_feedback,omitempty"`
 			ProjectScore    *string `json:"project_score,omitempty"`
 		} `json:"result,omitempty"`
-		Status *string `json:"status,omitempty"`
+		RubricVersion *string `json:"rubric_version,omitempty"`
+		Status        *string `json:"status,omitempty"`
+		Trace         *[]struct {
+			At     *string `json:"at,omitempty"`
+			Detail *string `json:"detail,omitempty"`
+			Stage  *string `json:"stage,omitempty"`
+		} `json:"trace,omitempty"`
+		Usage *struct {
+			CacheHits    *int     `json:"cache_hits,omitempty"`
+			Calls        *int     `json:"calls,omitempty"`
+			CostUsd      *float32 `json:"cost_usd,omitempty"`
+			OutputTokens *int     `json:"output_tokens,omitempty"`
+			PromptTokens *int     `json:"prompt_tokens,omitempty"`
+			TotalTokens  *int     `json:"total_tokens,omitempty"`
+		} `json:"usage,omitempty"`
+		Verification *map[string]struct {
+			Action *string `json:"action,omitempty"`
+			Claims *[]struct {
+				Claim    *string `json:"claim,omitempty"`
+				Evidence *string `json:"evidence,omitempty"`
+				Verdict  *string `json:"verdict,omitempty"`
+			} `json:"claims,omitempty"`
+			Error      *string `json:"error,omitempty"`
+			Mode       *string `json:"mode,omitempty"`
+			Ungrounded *int    `json:"ungrounded,omitempty"`
+		} `json:"verification,omitempty"`
+	} `json:"data,omitempty"`
+	Message *string `json:"message,omitempty"`
+	Status  *int    `json:"status,omitempty"`
+}
+
+// UsageResponse defines model for UsageResponse.
+type UsageResponse struct {
+	Data *struct {
+		From *string `json:"from,omitempty"`
+		Rows *[]struct {
+			Calls        *int     `json:"calls,omitempty"`
+			CostUsd      *float32 `json:"cost_usd,omitempty"`
+			Day          *string  `json:"day,omitempty"`
+			JobTitle     *string  `json:"job_title,omitempty"`
+			Model        *string  `json:"model,omitempty"`
+			OutputTokens *int     `json:"output_tokens,omitempty"`
+			PromptTokens *int     `json:"prompt_tokens,omitempty"`
+			TotalTokens  *int     `json:"total_tokens,omitempty"`
+		} `json:"rows,omitempty"`
+		To    *string `json:"to,omitempty"`
+		Total *struct {
+			Calls        *int     `json:"calls,omitempty"`
+			CostUsd      *float32 `json:"cost_usd,omitempty"`
+			OutputTokens *int     `json:"output_tokens,omitempty"`
+			PromptTokens *int     `json:"prompt_tokens,omitempty"`
+			TotalTokens  *int     `json:"total_tokens,omitempty"`
+		} `json:"total,omitempty"`
 	} `json:"data,omitempty"`
 	Message *string `json:"message,omitempty"`
 	Status  *int    `json:"status,omitempty"`
@@ -61,9 +211,36 @@ type UploadResponse struct {
 	Status  *int    `json:"status,omitempty"`
 }
 
+// UploadValidationResponse defines model for UploadValidationResponse.
+type UploadValidationResponse struct {
+	Errors *[]struct {
+		Code         *string `json:"code,omitempty"`
+		ContentType  *string `json:"content_type,omitempty"`
+		Field        *string `json:"field,omitempty"`
+		Filename     *string `json:"filename,omitempty"`
+		Message      *string `json:"message,omitempty"`
+		OriginalName *string `json:"original_name,omitempty"`
+		Pages        *int    `json:"pages,omitempty"`
+		SizeBytes    *int    `json:"size_bytes,omitempty"`
+		Valid        *bool   `json:"valid,omitempty"`
+		ValidatedAt  *string `json:"validated_at,omitempty"`
+	} `json:"errors,omitempty"`
+	Message *string `json:"message,omitempty"`
+	Status  *int    `json:"status,omitempty"`
+}
+
+// GetUsageParams defines parameters for GetUsage.
+type GetUsageParams struct {
+	From *string `form:"from,omitempty" json:"from,omitempty"`
+	To   *string `form:"to,omitempty" json:"to,omitempty"`
+}
+
 // PostEvaluateJSONRequestBody defines body for PostEvaluate for application/json ContentType.
 type PostEvaluateJSONRequestBody = EvaluateBodyRequest
 
+// PostJobsJobIdRerunJSONRequestBody defines body for PostJobsJobIdRerun for application/json ContentType.
+type PostJobsJobIdRerunJSONRequestBody = RerunBodyRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Evaluate the file that uploaded before
@@ -72,12 +249,24 @@ type ServerInterface interface {
 	// Endpoint Testing
 	// (GET /hello)
 	GetHello(w http.ResponseWriter, r *http.Request)
+	// Compare the result of two jobs
+	// (GET /jobs/{jobId}/compare/{otherJobId})
+	GetJobsJobIdCompareOtherJobId(w http.ResponseWriter, r *http.Request, jobId string, otherJobId string)
+	// Get the candidate profile parsed from the job CV
+	// (GET /jobs/{jobId}/profile)
+	GetJobsJobIdProfile(w http.ResponseWriter, r *http.Request, jobId string)
+	// Re-evaluate an existing job with overrides
+	// (POST /jobs/{jobId}/rerun)
+	PostJobsJobIdRerun(w http.ResponseWriter, r *http.Request, jobId string)
 	// Get the job result
 	// (GET /result/{jobId})
 	GetResultJobId(w http.ResponseWriter, r *http.Request, jobId string)
 	// Upload File
 	// (POST /upload)
 	PostUpload(w http.ResponseWriter, r *http.Request)
+	// Get token usage and cost by day, model and job title
+	// (GET /usage)
+	GetUsage(w http.ResponseWriter, r *http.Request, params GetUsageParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -119,6 +308,93 @@ func (siw *ServerInterfaceWrapper) GetHello(w http.ResponseWriter, r *http.Reque
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// GetJobsJobIdCompareOtherJobId operation middleware
+func (siw *ServerInterfaceWrapper) GetJobsJobIdCompareOtherJobId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameter("simple", false, "jobId", mux.Vars(r)["jobId"], &jobId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "jobId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "otherJobId" -------------
+	var otherJobId string
+
+	err = runtime.BindStyledParameter("simple", false, "otherJobId", mux.Vars(r)["otherJobId"], &otherJobId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "otherJobId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetJobsJobIdCompareOtherJobId(w, r, jobId, otherJobId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetJobsJobIdProfile operation middleware
+func (siw *ServerInterfaceWrapper) GetJobsJobIdProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameter("simple", false, "jobId", mux.Vars(r)["jobId"], &jobId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "jobId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetJobsJobIdProfile(w, r, jobId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostJobsJobIdRerun operation middleware
+func (siw *ServerInterfaceWrapper) PostJobsJobIdRerun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "jobId" -------------
+	var jobId string
+
+	err = runtime.BindStyledParameter("simple", false, "jobId", mux.Vars(r)["jobId"], &jobId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "jobId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostJobsJobIdRerun(w, r, jobId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // GetResultJobId operation middleware
 func (siw *ServerInterfaceWrapper) GetResultJobId(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -160,6 +436,42 @@ func (siw *ServerInterfaceWrapper) PostUpload(w http.ResponseWriter, r *http.Req
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// GetUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsageParams
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsage(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -277,9 +589,17 @@ func HandlerWithOptions(si ServerInterface, options GorillaServerOptions) http.H
 
 	r.HandleFunc(options.BaseURL+"/hello", wrapper.GetHello).Methods("GET")
 
+	r.HandleFunc(options.BaseURL+"/jobs/{jobId}/compare/{otherJobId}", wrapper.GetJobsJobIdCompareOtherJobId).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/jobs/{jobId}/profile", wrapper.GetJobsJobIdProfile).Methods("GET")
+
+	r.HandleFunc(options.BaseURL+"/jobs/{jobId}/rerun", wrapper.PostJobsJobIdRerun).Methods("POST")
+
 	r.HandleFunc(options.BaseURL+"/result/{jobId}", wrapper.GetResultJobId).Methods("GET")
 
 	r.HandleFunc(options.BaseURL+"/upload", wrapper.PostUpload).Methods("POST")
 
+	r.HandleFunc(options.BaseURL+"/usage", wrapper.GetUsage).Methods("GET")
+
 	return r
 }
@@ -0,0 +1,133 @@
+package biasblinder
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Attribute string
+
+const (
+	AttributeGender      Attribute = "gender"
+	AttributeAge         Attribute = "age"
+	AttributeNationality Attribute = "nationality"
+	AttributeSchool      Attribute = "school"
+	AttributePhoto       Attribute = "photo"
+	AttributeName        Attribute = "name"
+)
+
+// blindedName replaces the candidate name
+const blindedName = "[CANDIDATE]"
+
+type IBlinder interface {
+	// Blind neutralizes demographic signals and the candidate name, when known, and
+	// reports which attributes were touched.
+	Blind(text, candidateName string) (string, []Attribute)
+}
+
+type blinder struct{}
+
+func NewBlinder() IBlinder {
+	return &blinder{}
+}
+
+type blindRule struct {
+	attribute   Attribute
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+var (
+	labelledAttribute = `(?im)^([ \t#*-]*(?:%s)\s*[:\-]\s*)[^\n]+$`
+
+	blindRules = []blindRule{
+		{AttributeGender, regexp.MustCompile(labelled(`gender|sex|jenis\s+kelamin`)), "${1}[REMOVED]"},
+		{AttributeGender, regexp.MustCompile(`\b(?:Mr|Mrs|Ms|Miss|Mx)\.?\s+`), ""},
+		{AttributeAge, regexp.MustCompile(labelled(`age|usia|umur|date\s+of\s+birth|birth\s*date|place\s+and\s+date\s+of\s+birth|tempat(?:,)?\s+tanggal\s+lahir`)), "${1}[REMOVED]"},
+		{AttributeAge, regexp.MustCompile(`(?i)\b\d{2}\s*(?:years?|yrs?)\s*old\b`), "[AGE REMOVED]"},
+		{AttributeAge, regexp.MustCompile(`(?i)\bborn\s+(?:on\s+|in\s+)?[^\n,.;]+`), "[BIRTH REMOVED]"},
+		{AttributeNationality, regexp.MustCompile(labelled(`nationality|citizenship|kewarganegaraan|place\s+of\s+birth|religion|agama|ethnicity|marital\s+status|status\s+pernikahan`)), "${1}[REMOVED]"},
+		{AttributeSchool, regexp.MustCompile(`(?:\b\p{Lu}[\p{L}&'.\-]*\s+(?:of\s+|de\s+)?)*\b(?:University|Universitas|Institute|Institut|College|Polytechnic|Politeknik|School)\b(?:\s+(?:of|for)\s+\p{Lu}[\p{L}&'\-]*|\s+\p{Lu}[\p{L}&'\-]*)*`), "[INSTITUTION]"},
+		{AttributePhoto, regexp.MustCompile(labelled(`photo|photograph|picture|foto`)), "${1}[REMOVED]"},
+	}
+
+	pronouns = map[string]string{
+		"he": "they", "she": "they",
+		"him": "them",
+		"his": "their", "hers": "theirs",
+		"himself": "themselves", "herself": "themselves",
+	}
+	// "her" is either "them" or "their", "their" reads fine in CV sentences
+	herPattern     = regexp.MustCompile(`\b(?:[Hh]er)\b`)
+	pronounPattern = regexp.MustCompile(`\b(?i:he|she|him|his|hers|himself|herself)\b`)
+	// a name word is only blinded on its own when it is a plain word of 3 letters or more
+	nameWordPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z'\-]+[A-Za-z]$`)
+)
+
+func labelled(labels string) string {
+	return strings.Replace(labelledAttribute, "%s", labels, 1)
+}
+
+func (b *blinder) Blind(text, candidateName string) (string, []Attribute) {
+	touched := make(map[Attribute]bool)
+
+	if pattern := namePattern(candidateName); pattern != nil && pattern.MatchString(text) {
+		touched[AttributeName] = true
+		text = pattern.ReplaceAllString(text, blindedName)
+	}
+
+	for _, rule := range blindRules {
+		if rule.pattern.MatchString(text) {
+			touched[rule.attribute] = true
+			text = rule.pattern.ReplaceAllString(text, rule.replacement)
+		}
+	}
+
+	if pronounPattern.MatchString(text) || herPattern.MatchString(text) {
+		touched[AttributeGender] = true
+		text = pronounPattern.ReplaceAllStringFunc(text, func(match string) string {
+			return matchCase(match, pronouns[strings.ToLower(match)])
+		})
+		text = herPattern.ReplaceAllStringFunc(text, func(match string) string {
+			return matchCase(match, "their")
+		})
+	}
+
+	attributes := make([]Attribute, 0, len(touched))
+	for attribute := range touched {
+		attributes = append(attributes, attribute)
+	}
+	sort.Slice(attributes, func(a, b int) bool { return attributes[a] < attributes[b] })
+
+	return text, attributes
+}
+
+// namePattern matches the whole name, a PII placeholder included, and each of its words
+// so "Jane" and "Doe" are blinded too.
+func namePattern(candidateName string) *regexp.Regexp {
+	candidateName = strings.TrimSpace(candidateName)
+	if candidateName == "" {
+		return nil
+	}
+
+	var words []string
+	for _, word := range strings.Fields(candidateName) {
+		if word != candidateName && nameWordPattern.MatchString(word) {
+			words = append(words, regexp.QuoteMeta(word))
+		}
+	}
+
+	pattern := `(?i)` + regexp.QuoteMeta(candidateName)
+	if len(words) > 0 {
+		pattern += `|\b(?:` + strings.Join(words, "|") + `)\b`
+	}
+	return regexp.MustCompile(pattern)
+}
+
+func matchCase(original, replacement string) string {
+	if original != "" && original[0] >= 'A' && original[0] <= 'Z' {
+		return strings.ToUpper(replacement[:1]) + replacement[1:]
+	}
+	return replacement
+}
@@ -0,0 +1,87 @@
+package biasblinder
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+var (
+	femaleSignal     = regexp.MustCompile(`(?i)\b(?:she|her|hers|herself|mrs|ms|miss)\b`)
+	namePlaceholder  = regexp.MustCompile(`\[NAME_\d+\]`)
+	genderSwapTokens = map[string]string{
+		"he": "she", "she": "he",
+		"him": "her", "his": "her", "hers": "his",
+		"himself": "herself", "herself": "himself",
+		"her": "his",
+		"mr":  "ms", "ms": "mr", "mrs": "mr", "miss": "mr",
+	}
+	genderSwapPattern = regexp.MustCompile(`\b(?i:he|she|him|his|her|hers|himself|herself|mr|ms|mrs|miss)\b`)
+)
+
+// counterfactual names, one per signal, the family name is shared so only the gender
+// signal changes
+const (
+	counterfactualMaleName   = "James Miller"
+	counterfactualFemaleName = "Emily Miller"
+)
+
+type Counterfactual struct {
+	Text string
+	// Variant describes the swap, e.g. "name=Emily Miller, pronouns swapped"
+	Variant string
+}
+
+// BuildCounterfactual swaps gendered pronouns and titles and puts a name carrying the
+// opposite gender signal where the candidate name is, either a PII placeholder or the
+// given name. It expects the text before blinding, a blinded text has no gender signal
+// left to swap.
+func BuildCounterfactual(text, candidateName string) Counterfactual {
+	name := counterfactualFemaleName
+	if femaleSignal.MatchString(text) {
+		name = counterfactualMaleName
+	}
+
+	swaps := 0
+	swapped := genderSwapPattern.ReplaceAllStringFunc(text, func(match string) string {
+		replacement, ok := genderSwapTokens[strings.ToLower(match)]
+		if !ok {
+			return match
+		}
+		swaps++
+		return matchCase(match, replacement)
+	})
+
+	if namePlaceholder.MatchString(swapped) {
+		swapped = namePlaceholder.ReplaceAllString(swapped, name)
+	} else if candidateName != "" {
+		swapped = strings.ReplaceAll(swapped, candidateName, name)
+	} else {
+		swapped = "Candidate name: " + name + "\n" + swapped
+	}
+
+	variant := "name=" + name
+	if swaps > 0 {
+		variant += ", gendered pronouns and titles swapped"
+	}
+
+	return Counterfactual{
+		Text:    swapped,
+		Variant: variant,
+	}
+}
+
+// Sampled picks a stable subset of keys, the same job is always in or out of the
+// sample so reruns stay comparable.
+func Sampled(key string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum32()%10000) < rate*10000
+}
@@ -0,0 +1,71 @@
+package chromaclient
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const DefaultEmbeddingDim = 384
+
+// IEmbedder turns a text into the vector compared by cosine similarity
+type IEmbedder interface {
+	Embed(text string) []float32
+}
+
+type termHashEmbedder struct {
+	dim int
+}
+
+// NewTermHashEmbedder hashes every lowercase word of the text into one of dim buckets, the
+// vector is normalized so texts sharing words have a high cosine similarity. It needs no
+// model, which keeps the memory store usable offline.
+func NewTermHashEmbedder(dim int) IEmbedder {
+	if dim <= 0 {
+		dim = DefaultEmbeddingDim
+	}
+	return &termHashEmbedder{dim: dim}
+}
+
+func (e *termHashEmbedder) Embed(text string) []float32 {
+	vector := make([]float32, e.dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		hasher := fnv.New32a()
+		hasher.Write([]byte(word))
+		vector[hasher.Sum32()%uint32(e.dim)]++
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for idx := range vector {
+		vector[idx] = float32(float64(vector[idx]) / norm)
+	}
+	return vector
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for idx := range a {
+		dot += float64(a[idx]) * float64(b[idx])
+		normA += float64(a[idx]) * float64(a[idx])
+		normB += float64(b[idx]) * float64(b[idx])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -2,13 +2,20 @@ package chromaclient
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 	chroma "github.com/amikos-tech/chroma-go/pkg/api/v2"
+	chhttp "github.com/amikos-tech/chroma-go/pkg/commons/http"
 	"github.com/amikos-tech/chroma-go/pkg/embeddings"
 )
 
+var ErrUnsupportedMetadata = errors.New("error unsupported metadata type")
+
 type ChromaNotFoundRecord struct {
 	Query          string
 	CollectionName string
@@ -20,11 +27,97 @@ func (c *ChromaNotFoundRecord) Error() string {
 
 type IChromaClient interface {
 	Upsert(ctx context.Context, collectionName, id, content string, metadata map[string]interface{}) error
-	Query(ctx context.Context, collectionName, query string, topK int) ([]models.ChromaSearchResult, error)
+	UpsertBatch(ctx context.Context, collectionName string, documents []models.ChromaDocument) error
+	Get(ctx context.Context, collectionName string, opts ...QueryOption) ([]models.ChromaDocument, error)
+	UpdateMetadata(ctx context.Context, collectionName string, documents []models.ChromaDocument) error
+	Delete(ctx context.Context, collectionName string, ids []string) error
+	Query(ctx context.Context, collectionName, query string, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error)
+}
+
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	where    map[string]string
+	whereNot map[string]string
+}
+
+// Options
+func WithWhereEq(key, value string) QueryOption {
+	return func(c *queryConfig) {
+		if c.where == nil {
+			c.where = make(map[string]string)
+		}
+		c.where[key] = value
+	}
+}
+
+// WithWhereNotEq excludes records whose key equals value. The embedded stores also match
+// records without the key, Chroma does not promise that, so keep the key on every record.
+func WithWhereNotEq(key, value string) QueryOption {
+	return func(c *queryConfig) {
+		if c.whereNot == nil {
+			c.whereNot = make(map[string]string)
+		}
+		c.whereNot[key] = value
+	}
+}
+
+func (q *queryConfig) whereFilter() chroma.WhereFilter {
+	if len(q.where) == 0 && len(q.whereNot) == 0 {
+		return nil
+	}
+
+	var clauses []chroma.WhereClause
+	for k, v := range q.where {
+		clauses = append(clauses, chroma.EqString(k, v))
+	}
+	for k, v := range q.whereNot {
+		clauses = append(clauses, chroma.NotEqString(k, v))
+	}
+
+	if len(clauses) == 1 {
+		return clauses[0]
+	}
+	return chroma.And(clauses...)
+}
+
+// the v2 client has no constant for it, the server returns distances when asked
+const includeDistances chroma.Include = "distances"
+
+// DistanceScore maps a vector distance to a 0..1 relevance, 1 is an exact match.
+func DistanceScore(distance float64) float64 {
+	if distance < 0 {
+		distance = 0
+	}
+	return 1 / (1 + distance)
+}
+
+// MatchMetadata evaluates the where options against metadata in process, used to apply
+// the same filters to records that did not come from a Chroma query.
+func MatchMetadata(metadata map[string]interface{}, opts ...QueryOption) bool {
+	queryCfg := &queryConfig{}
+	for _, opt := range opts {
+		opt(queryCfg)
+	}
+
+	for k, v := range queryCfg.where {
+		if value, ok := metadata[k].(string); !ok || value != v {
+			return false
+		}
+	}
+	for k, v := range queryCfg.whereNot {
+		if value, ok := metadata[k].(string); ok && value == v {
+			return false
+		}
+	}
+	return true
 }
 
 type chromaClient struct {
 	cli chroma.Client
+
+	mu          sync.Mutex
+	collections map[string]chroma.Collection
 }
 
 func NewChromaClient(ctx context.Context, chromaUrl string) (IChromaClient, error) {
@@ -37,15 +130,209 @@ func NewChromaClient(ctx context.Context, chromaUrl string) (IChromaClient, erro
 		return nil, err
 	}
 
-	return &chromaClient{cli: client}, nil
+	return &chromaClient{cli: client, collections: make(map[string]chroma.Collection)}, nil
+}
+
+// collection returns the cached handle, the collection is only created on the write path.
+// Without create a missing collection is ErrCollectionNotFound.
+func (c *chromaClient) collection(ctx context.Context, collectionName string, create bool) (chroma.Collection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if collection, ok := c.collections[collectionName]; ok {
+		return collection, nil
+	}
+
+	embedding := embeddings.NewConsistentHashEmbeddingFunction()
+	var collection chroma.Collection
+	var err error
+	if create {
+		collection, err = c.cli.GetOrCreateCollection(ctx, collectionName, chroma.WithEmbeddingFunctionCreate(embedding))
+	} else {
+		collection, err = c.cli.GetCollection(ctx, collectionName, chroma.WithEmbeddingFunctionGet(embedding))
+	}
+	if err != nil {
+		var chromaErr *chhttp.ChromaError
+		if !create && errors.As(err, &chromaErr) && (chromaErr.ErrorCode == http.StatusNotFound || chromaErr.ErrorID == "NotFoundError") {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+
+	c.collections[collectionName] = collection
+	return collection, nil
+}
+
+// forget drops a cached handle after a failed call, the collection may have been
+// deleted or recreated on the server.
+func (c *chromaClient) forget(collectionName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.collections, collectionName)
 }
 
 func (c *chromaClient) Upsert(ctx context.Context, collectionName, id, content string, metadata map[string]interface{}) error {
-	collection, err := c.cli.GetOrCreateCollection(ctx, collectionName, chroma.WithEmbeddingFunctionCreate(embeddings.NewConsistentHashEmbeddingFunction()))
+	return c.UpsertBatch(ctx, collectionName, []models.ChromaDocument{{Id: id, Content: content, Metadata: metadata}})
+}
+
+// UpsertBatch writes all documents in a single request.
+func (c *chromaClient) UpsertBatch(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	collection, err := c.collection(ctx, collectionName, true)
 	if err != nil {
 		return fmt.Errorf("failed to create collection: %w", err)
 	}
 
+	ids := make([]chroma.DocumentID, 0, len(documents))
+	texts := make([]string, 0, len(documents))
+	metadatas := make([]chroma.DocumentMetadata, 0, len(documents))
+	for _, document := range documents {
+		metadata, err := toDocumentMetadata(document.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to upsert document %s: %w", document.Id, err)
+		}
+		ids = append(ids, chroma.DocumentID(document.Id))
+		texts = append(texts, document.Content)
+		metadatas = append(metadatas, metadata)
+	}
+
+	if err := collection.Upsert(ctx,
+		chroma.WithIDs(ids...),
+		chroma.WithTexts(texts...),
+		chroma.WithMetadatas(metadatas...)); err != nil {
+		c.forget(collectionName)
+		return fmt.Errorf("failed to upsert document: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the stored records matching the filters with their metadata, a missing
+// collection has no records.
+func (c *chromaClient) Get(ctx context.Context, collectionName string, opts ...QueryOption) ([]models.ChromaDocument, error) {
+	queryCfg := &queryConfig{}
+	for _, opt := range opts {
+		opt(queryCfg)
+	}
+
+	collection, err := c.collection(ctx, collectionName, false)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return []models.ChromaDocument{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	getOptions := []chroma.CollectionGetOption{
+		chroma.WithIncludeGet(chroma.IncludeDocuments, chroma.IncludeMetadatas),
+	}
+	if where := queryCfg.whereFilter(); where != nil {
+		getOptions = append(getOptions, chroma.WithWhereGet(where))
+	}
+
+	resp, err := collection.Get(ctx, getOptions...)
+	if err != nil {
+		c.forget(collectionName)
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	ids := resp.GetIDs()
+	docs := resp.GetDocuments()
+	metadatas := resp.GetMetadatas()
+
+	documents := make([]models.ChromaDocument, 0, len(ids))
+	for i, id := range ids {
+		document := models.ChromaDocument{Id: string(id)}
+		if i < len(docs) && docs[i] != nil {
+			document.Content = docs[i].ContentString()
+		}
+		if i < len(metadatas) && metadatas[i] != nil {
+			document.Metadata = fromDocumentMetadata(metadatas[i])
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// UpdateMetadata replaces the metadata of existing records, content and embeddings stay.
+func (c *chromaClient) UpdateMetadata(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	collection, err := c.collection(ctx, collectionName, false)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	ids := make([]chroma.DocumentID, 0, len(documents))
+	metadatas := make([]chroma.DocumentMetadata, 0, len(documents))
+	for _, document := range documents {
+		metadata, err := toDocumentMetadata(document.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to update document %s: %w", document.Id, err)
+		}
+		ids = append(ids, chroma.DocumentID(document.Id))
+		metadatas = append(metadatas, metadata)
+	}
+
+	if err := collection.Update(ctx, chroma.WithIDsUpdate(ids...), chroma.WithMetadatasUpdate(metadatas...)); err != nil {
+		c.forget(collectionName)
+		return fmt.Errorf("failed to update documents: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes all ids in a single request, a missing collection has nothing to delete.
+func (c *chromaClient) Delete(ctx context.Context, collectionName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	collection, err := c.collection(ctx, collectionName, false)
+	if errors.Is(err, ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	documentIds := make([]chroma.DocumentID, 0, len(ids))
+	for _, id := range ids {
+		documentIds = append(documentIds, chroma.DocumentID(id))
+	}
+
+	if err := collection.Delete(ctx, chroma.WithIDsDelete(documentIds...)); err != nil {
+		c.forget(collectionName)
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	return nil
+}
+
+func fromDocumentMetadata(metadata chroma.DocumentMetadata) map[string]interface{} {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// toDocumentMetadata fails on a value Chroma cannot store instead of dropping the key.
+func toDocumentMetadata(metadata map[string]interface{}) (chroma.DocumentMetadata, error) {
 	var metaAttributes []*chroma.MetaAttribute
 	for k, v := range metadata {
 		switch val := v.(type) {
@@ -53,38 +340,46 @@ func (c *chromaClient) Upsert(ctx context.Context, collectionName, id, content s
 			metaAttributes = append(metaAttributes, chroma.NewStringAttribute(k, val))
 		case int:
 			metaAttributes = append(metaAttributes, chroma.NewIntAttribute(k, int64(val)))
+		case int64:
+			metaAttributes = append(metaAttributes, chroma.NewIntAttribute(k, val))
 		case float64:
 			metaAttributes = append(metaAttributes, chroma.NewFloatAttribute(k, val))
+		case bool:
+			metaAttributes = append(metaAttributes, chroma.NewBoolAttribute(k, val))
 		default:
-			fmt.Printf("Warning: Unsupported metadata type for key '%s'\n", k)
+			return nil, fmt.Errorf("%w %T for key %s", ErrUnsupportedMetadata, v, k)
 		}
 	}
 
-	if err := collection.Upsert(ctx,
-		chroma.WithIDs(chroma.DocumentID(id)),
-		chroma.WithTexts(content),
-		chroma.WithMetadatas(chroma.NewDocumentMetadata(metaAttributes...))); err != nil {
-		return fmt.Errorf("failed to upsert document: %w", err)
-	}
-
-	return nil
+	return chroma.NewDocumentMetadata(metaAttributes...), nil
 }
 
-func (c *chromaClient) Query(ctx context.Context, collectionName, query string, topK int) ([]models.ChromaSearchResult, error) {
+func (c *chromaClient) Query(ctx context.Context, collectionName, query string, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error) {
+	queryCfg := &queryConfig{}
+	for _, opt := range opts {
+		opt(queryCfg)
+	}
+
 	embedding := embeddings.NewConsistentHashEmbeddingFunction()
-	collection, err := c.cli.GetCollection(ctx, collectionName, chroma.WithEmbeddingFunctionGet(embedding))
+	collection, err := c.collection(ctx, collectionName, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get collection: %w", err)
 	}
 
 	embeddingQuery, _ := embedding.EmbedQuery(ctx, query)
-	resp, err := collection.Query(ctx,
+	queryOptions := []chroma.CollectionQueryOption{
 		chroma.WithNResults(topK),
 		chroma.WithQueryEmbeddings(embeddingQuery),
-		chroma.WithIncludeQuery(chroma.IncludeDocuments, chroma.IncludeMetadatas),
-	)
+		chroma.WithIncludeQuery(chroma.IncludeDocuments, chroma.IncludeMetadatas, includeDistances),
+	}
+	if where := queryCfg.whereFilter(); where != nil {
+		queryOptions = append(queryOptions, chroma.WithWhereQuery(where))
+	}
+
+	resp, err := collection.Query(ctx, queryOptions...)
 
 	if err != nil {
+		c.forget(collectionName)
 		return nil, fmt.Errorf("failed to query collection: %w", err)
 	}
 
@@ -95,12 +390,35 @@ func (c *chromaClient) Query(ctx context.Context, collectionName, query string,
 	var results []models.ChromaSearchResult
 	idGroup := resp.GetIDGroups()[0]
 	docsGroup := resp.GetDocumentsGroups()[0]
+	var distances []float64
+	if groups := resp.GetDistancesGroups(); len(groups) > 0 {
+		for _, distance := range groups[0] {
+			distances = append(distances, float64(distance))
+		}
+	}
+	var metadatas []map[string]interface{}
+	if groups := resp.GetMetadatasGroups(); len(groups) > 0 {
+		for _, metadata := range groups[0] {
+			if metadata == nil {
+				metadatas = append(metadatas, nil)
+				continue
+			}
+			metadatas = append(metadatas, fromDocumentMetadata(metadata))
+		}
+	}
 
 	for i, id := range idGroup {
 		result := models.ChromaSearchResult{
 			Id:   string(id),
 			Text: docsGroup[i].ContentString(),
 		}
+		if i < len(distances) {
+			result.Distance = distances[i]
+			result.Score = DistanceScore(distances[i])
+		}
+		if i < len(metadatas) {
+			result.Metadata = metadatas[i]
+		}
 
 		results = append(results, result)
 	}
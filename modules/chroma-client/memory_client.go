@@ -0,0 +1,208 @@
+package chromaclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+type memoryClient struct {
+	embedder     IEmbedder
+	snapshotPath string
+
+	mu          sync.RWMutex
+	collections map[string]map[string]*vectorRecord
+}
+
+// memorySnapshot is the file written by a snapshotting store, the embeddings are not
+// stored and are computed again on load.
+type memorySnapshot struct {
+	Collections map[string][]models.ChromaDocument `json:"collections"`
+}
+
+// NewMemoryChromaClient keeps the collections in process and ranks a query by cosine
+// similarity, the distance of a result is 1 - similarity. Like Chroma, writes create the
+// collection and querying a missing collection fails.
+func NewMemoryChromaClient(opts ...EmbeddedStoreOption) IChromaClient {
+	return &memoryClient{
+		embedder:    newEmbeddedConfig(opts...).embedder,
+		collections: make(map[string]map[string]*vectorRecord),
+	}
+}
+
+// NewSnapshotChromaClient is the memory store loaded from path, every write saves the
+// whole store back to it so the knowledge base survives a restart.
+func NewSnapshotChromaClient(path string, opts ...EmbeddedStoreOption) (IChromaClient, error) {
+	client := NewMemoryChromaClient(opts...).(*memoryClient)
+	client.snapshotPath = path
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return client, nil
+		}
+		return nil, err
+	}
+
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid vector store snapshot %s: %s", path, err.Error())
+	}
+	for collectionName, documents := range snapshot.Collections {
+		collection := client.collection(collectionName)
+		for _, document := range documents {
+			collection[document.Id] = &vectorRecord{document: document, embedding: client.embedder.Embed(document.Content)}
+		}
+	}
+
+	return client, nil
+}
+
+func (m *memoryClient) Upsert(ctx context.Context, collectionName, id, content string, metadata map[string]interface{}) error {
+	return m.UpsertBatch(ctx, collectionName, []models.ChromaDocument{{Id: id, Content: content, Metadata: metadata}})
+}
+
+func (m *memoryClient) UpsertBatch(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collection := m.collection(collectionName)
+	for _, document := range documents {
+		collection[document.Id] = &vectorRecord{
+			document:  copyDocument(document),
+			embedding: m.embedder.Embed(document.Content),
+		}
+	}
+
+	return m.saveSnapshot()
+}
+
+// Get reads a missing collection as empty without creating it.
+func (m *memoryClient) Get(ctx context.Context, collectionName string, opts ...QueryOption) ([]models.ChromaDocument, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	documents := []models.ChromaDocument{}
+	for _, record := range sortedRecords(m.collections[collectionName]) {
+		if MatchMetadata(record.document.Metadata, opts...) {
+			documents = append(documents, copyDocument(record.document))
+		}
+	}
+
+	return documents, nil
+}
+
+// UpdateMetadata replaces the metadata of existing records, unknown ids are skipped and
+// the snapshot is only saved when a record changed.
+func (m *memoryClient) UpdateMetadata(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := false
+	collection := m.collections[collectionName]
+	for _, document := range documents {
+		if record, ok := collection[document.Id]; ok {
+			record.document.Metadata = copyMetadata(document.Metadata)
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+
+	return m.saveSnapshot()
+}
+
+// Delete skips unknown ids and missing collections, the snapshot is only saved when a
+// record was removed.
+func (m *memoryClient) Delete(ctx context.Context, collectionName string, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := false
+	collection := m.collections[collectionName]
+	for _, id := range ids {
+		if _, ok := collection[id]; ok {
+			delete(collection, id)
+			deleted = true
+		}
+	}
+	if !deleted {
+		return nil
+	}
+
+	return m.saveSnapshot()
+}
+
+func (m *memoryClient) Query(ctx context.Context, collectionName, query string, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collection, ok := m.collections[collectionName]
+	if !ok {
+		return nil, fmt.Errorf("failed to get collection: %w", ErrCollectionNotFound)
+	}
+
+	return rankRecords(collectionName, query, m.embedder.Embed(query), sortedRecords(collection), topK, opts...)
+}
+
+// collection returns the records of collectionName, creating it, only writes call it.
+// Callers hold the lock.
+func (m *memoryClient) collection(collectionName string) map[string]*vectorRecord {
+	collection, ok := m.collections[collectionName]
+	if !ok {
+		collection = make(map[string]*vectorRecord)
+		m.collections[collectionName] = collection
+	}
+	return collection
+}
+
+// saveSnapshot writes the whole store next to the snapshot and renames it, a crash mid
+// write keeps the previous snapshot. Callers hold the lock.
+func (m *memoryClient) saveSnapshot() error {
+	if m.snapshotPath == "" {
+		return nil
+	}
+
+	snapshot := memorySnapshot{Collections: make(map[string][]models.ChromaDocument, len(m.collections))}
+	for collectionName, collection := range m.collections {
+		documents := make([]models.ChromaDocument, 0, len(collection))
+		for _, record := range sortedRecords(collection) {
+			documents = append(documents, record.document)
+		}
+		snapshot.Collections[collectionName] = documents
+	}
+
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp := m.snapshotPath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.snapshotPath)
+}
+
+// sortedRecords orders the records by id so results do not depend on map order.
+func sortedRecords(collection map[string]*vectorRecord) []*vectorRecord {
+	records := make([]*vectorRecord, 0, len(collection))
+	for _, record := range collection {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(a, b int) bool { return records[a].document.Id < records[b].document.Id })
+	return records
+}
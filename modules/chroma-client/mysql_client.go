@@ -0,0 +1,142 @@
+package chromaclient
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mysqlClient struct {
+	db       *gorm.DB
+	embedder IEmbedder
+}
+
+// NewMysqlChromaClient keeps the collections in the vector_record table and answers a
+// query by loading the collection and ranking it by cosine similarity, meant for the
+// knowledge base sizes of a single deployment. A collection without records counts as
+// missing.
+func NewMysqlChromaClient(db *gorm.DB, opts ...EmbeddedStoreOption) IChromaClient {
+	return &mysqlClient{db: db, embedder: newEmbeddedConfig(opts...).embedder}
+}
+
+func (m *mysqlClient) Upsert(ctx context.Context, collectionName, id, content string, metadata map[string]interface{}) error {
+	return m.UpsertBatch(ctx, collectionName, []models.ChromaDocument{{Id: id, Content: content, Metadata: metadata}})
+}
+
+func (m *mysqlClient) UpsertBatch(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	records := make([]*dao.VectorRecord, 0, len(documents))
+	for _, document := range documents {
+		records = append(records, &dao.VectorRecord{
+			CollectionName: collectionName,
+			RecordId:       document.Id,
+			Content:        document.Content,
+			Metadata:       document.Metadata,
+			Embedding:      encodeEmbedding(m.embedder.Embed(document.Content)),
+		})
+	}
+
+	return m.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"content", "metadata", "embedding"}),
+	}).Create(&records).Error
+}
+
+func (m *mysqlClient) Get(ctx context.Context, collectionName string, opts ...QueryOption) ([]models.ChromaDocument, error) {
+	records, err := m.collectionRecords(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := []models.ChromaDocument{}
+	for _, record := range records {
+		if MatchMetadata(record.document.Metadata, opts...) {
+			documents = append(documents, record.document)
+		}
+	}
+
+	return documents, nil
+}
+
+// UpdateMetadata replaces the metadata of existing records, unknown ids are skipped.
+func (m *mysqlClient) UpdateMetadata(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, document := range documents {
+			err := tx.Model(&dao.VectorRecord{}).
+				Where("collection_name = ? AND record_id = ?", collectionName, document.Id).
+				Select("metadata").
+				Updates(&dao.VectorRecord{Metadata: document.Metadata}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *mysqlClient) Delete(ctx context.Context, collectionName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return m.db.WithContext(ctx).
+		Where("collection_name = ? AND record_id IN ?", collectionName, ids).
+		Delete(&dao.VectorRecord{}).Error
+}
+
+func (m *mysqlClient) Query(ctx context.Context, collectionName, query string, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error) {
+	records, err := m.collectionRecords(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("failed to get collection: %w", ErrCollectionNotFound)
+	}
+
+	return rankRecords(collectionName, query, m.embedder.Embed(query), records, topK, opts...)
+}
+
+// collectionRecords loads every record of the collection ordered by id.
+func (m *mysqlClient) collectionRecords(ctx context.Context, collectionName string) ([]*vectorRecord, error) {
+	var rows []dao.VectorRecord
+	err := m.db.WithContext(ctx).
+		Where("collection_name = ?", collectionName).
+		Order("record_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*vectorRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, &vectorRecord{
+			document:  models.ChromaDocument{Id: row.RecordId, Content: row.Content, Metadata: row.Metadata},
+			embedding: decodeEmbedding(row.Embedding),
+		})
+	}
+	return records, nil
+}
+
+func encodeEmbedding(embedding []float32) []byte {
+	raw := make([]byte, 4*len(embedding))
+	for idx, value := range embedding {
+		binary.LittleEndian.PutUint32(raw[4*idx:], math.Float32bits(value))
+	}
+	return raw
+}
+
+func decodeEmbedding(raw []byte) []float32 {
+	embedding := make([]float32, len(raw)/4)
+	for idx := range embedding {
+		embedding[idx] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*idx:]))
+	}
+	return embedding
+}
@@ -0,0 +1,195 @@
+package chromaclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pgVectorRow is a vector_record row read back, metadata is selected as json text
+type pgVectorRow struct {
+	RecordId string
+	Content  string
+	Metadata string
+	Distance float64
+}
+
+type pgvectorClient struct {
+	db       *gorm.DB
+	embedder IEmbedder
+}
+
+// pgvectorSchema is created by the store rather than by a migration, only databases that
+// keep the knowledge base need the vector extension.
+var pgvectorSchema = []string{
+	"CREATE EXTENSION IF NOT EXISTS vector",
+	fmt.Sprintf(`CREATE TABLE IF NOT EXISTS vector_record (
+    collection_name VARCHAR(191),
+    record_id       VARCHAR(191),
+    content         TEXT,
+    metadata        JSONB,
+    embedding       VECTOR(%d),
+    PRIMARY KEY (collection_name, record_id)
+)`, DefaultEmbeddingDim),
+	"CREATE INDEX IF NOT EXISTS idx_vector_record_embedding ON vector_record USING hnsw (embedding vector_cosine_ops)",
+}
+
+// NewPgvectorChromaClient keeps the collections in the vector_record table of PostgreSQL,
+// the nearest records by cosine distance and the metadata filters are computed by the
+// database. The extension and the table are created when missing, the embedding column
+// is sized for DefaultEmbeddingDim.
+func NewPgvectorChromaClient(ctx context.Context, db *gorm.DB, opts ...EmbeddedStoreOption) (IChromaClient, error) {
+	for _, statement := range pgvectorSchema {
+		if err := db.WithContext(ctx).Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("failed to create the pgvector schema: %w", err)
+		}
+	}
+
+	return &pgvectorClient{db: db, embedder: newEmbeddedConfig(opts...).embedder}, nil
+}
+
+func (p *pgvectorClient) Upsert(ctx context.Context, collectionName, id, content string, metadata map[string]interface{}) error {
+	return p.UpsertBatch(ctx, collectionName, []models.ChromaDocument{{Id: id, Content: content, Metadata: metadata}})
+}
+
+func (p *pgvectorClient) UpsertBatch(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	records := make([]*dao.PgVectorRecord, 0, len(documents))
+	for _, document := range documents {
+		records = append(records, &dao.PgVectorRecord{
+			CollectionName: collectionName,
+			RecordId:       document.Id,
+			Content:        document.Content,
+			Metadata:       document.Metadata,
+			Embedding:      pgvector.NewVector(p.embedder.Embed(document.Content)),
+		})
+	}
+
+	return p.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "collection_name"}, {Name: "record_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "metadata", "embedding"}),
+	}).Create(&records).Error
+}
+
+func (p *pgvectorClient) Get(ctx context.Context, collectionName string, opts ...QueryOption) ([]models.ChromaDocument, error) {
+	var rows []pgVectorRow
+	err := p.filtered(ctx, collectionName, opts...).
+		Select("record_id, content, metadata::text AS metadata").
+		Order("record_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]models.ChromaDocument, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, models.ChromaDocument{Id: row.RecordId, Content: row.Content, Metadata: decodeMetadata(row.Metadata)})
+	}
+	return documents, nil
+}
+
+// UpdateMetadata replaces the metadata of existing records, unknown ids are skipped.
+func (p *pgvectorClient) UpdateMetadata(ctx context.Context, collectionName string, documents []models.ChromaDocument) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, document := range documents {
+			err := tx.Model(&dao.PgVectorRecord{}).
+				Where("collection_name = ? AND record_id = ?", collectionName, document.Id).
+				Select("metadata").
+				Updates(&dao.PgVectorRecord{Metadata: document.Metadata}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *pgvectorClient) Delete(ctx context.Context, collectionName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return p.db.WithContext(ctx).
+		Where("collection_name = ? AND record_id IN ?", collectionName, ids).
+		Delete(&dao.PgVectorRecord{}).Error
+}
+
+func (p *pgvectorClient) Query(ctx context.Context, collectionName, query string, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error) {
+	embedding := pgvector.NewVector(p.embedder.Embed(query))
+	search := p.filtered(ctx, collectionName, opts...).
+		Select("record_id, content, metadata::text AS metadata, embedding <=> ? AS distance", embedding).
+		Order("distance, record_id")
+	if topK > 0 {
+		search = search.Limit(topK)
+	}
+
+	var rows []pgVectorRow
+	if err := search.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		var exists bool
+		err := p.db.WithContext(ctx).
+			Raw("SELECT EXISTS (SELECT 1 FROM vector_record WHERE collection_name = ?)", collectionName).
+			Scan(&exists).Error
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("failed to get collection: %w", ErrCollectionNotFound)
+		}
+		return nil, &ChromaNotFoundRecord{CollectionName: collectionName, Query: query}
+	}
+
+	results := make([]models.ChromaSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, models.ChromaSearchResult{
+			Id:       row.RecordId,
+			Text:     row.Content,
+			Distance: row.Distance,
+			Score:    DistanceScore(row.Distance),
+			Metadata: decodeMetadata(row.Metadata),
+		})
+	}
+	return results, nil
+}
+
+// filtered is the collection with the metadata filters applied the way MatchMetadata
+// does, a record without the key passes a not equal filter.
+func (p *pgvectorClient) filtered(ctx context.Context, collectionName string, opts ...QueryOption) *gorm.DB {
+	queryCfg := &queryConfig{}
+	for _, opt := range opts {
+		opt(queryCfg)
+	}
+
+	tx := p.db.WithContext(ctx).Model(&dao.PgVectorRecord{}).Where("collection_name = ?", collectionName)
+	for k, v := range queryCfg.where {
+		tx = tx.Where("metadata ->> ? = ?", k, v)
+	}
+	for k, v := range queryCfg.whereNot {
+		tx = tx.Where("(metadata ->> ? IS NULL OR metadata ->> ? <> ?)", k, k, v)
+	}
+	return tx
+}
+
+func decodeMetadata(raw string) map[string]interface{} {
+	if raw == "" {
+		return nil
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return nil
+	}
+	return metadata
+}
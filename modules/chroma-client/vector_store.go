@@ -0,0 +1,99 @@
+package chromaclient
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+// Vector stores selectable in place of a Chroma server
+const (
+	StoreChroma = "chroma"
+	// StoreMemory keeps the vectors in process, optionally snapshotted to a file
+	StoreMemory = "memory"
+	// StoreMysql keeps the vectors as blobs in the vector_record table
+	StoreMysql = "mysql"
+	// StorePgvector keeps the vectors in the pgvector column of the vector_record table
+	StorePgvector = "pgvector"
+)
+
+var ErrCollectionNotFound = errors.New("error collection not found")
+
+type EmbeddedStoreOption func(*embeddedConfig)
+
+type embeddedConfig struct {
+	embedder IEmbedder
+}
+
+func newEmbeddedConfig(opts ...EmbeddedStoreOption) *embeddedConfig {
+	config := &embeddedConfig{embedder: NewTermHashEmbedder(DefaultEmbeddingDim)}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// Options
+// WithEmbedder replaces the term hashing embedding of the memory and mysql stores, the
+// knowledge base has to be ingested again after a change
+func WithEmbedder(embedder IEmbedder) EmbeddedStoreOption {
+	return func(c *embeddedConfig) {
+		if embedder != nil {
+			c.embedder = embedder
+		}
+	}
+}
+
+// vectorRecord is a stored document with the embedding of its content
+type vectorRecord struct {
+	document  models.ChromaDocument
+	embedding []float32
+}
+
+// rankRecords is the brute force search of the embedded stores, every record matching the
+// filters is compared with the query and the topK closest are returned by distance, the
+// distance being 1 - cosine similarity.
+func rankRecords(collectionName, query string, embedding []float32, records []*vectorRecord, topK int, opts ...QueryOption) ([]models.ChromaSearchResult, error) {
+	var results []models.ChromaSearchResult
+	for _, record := range records {
+		if !MatchMetadata(record.document.Metadata, opts...) {
+			continue
+		}
+
+		distance := 1 - cosineSimilarity(embedding, record.embedding)
+		results = append(results, models.ChromaSearchResult{
+			Id:       record.document.Id,
+			Text:     record.document.Content,
+			Distance: distance,
+			Score:    DistanceScore(distance),
+			Metadata: copyMetadata(record.document.Metadata),
+		})
+	}
+
+	if len(results) == 0 {
+		return nil, &ChromaNotFoundRecord{CollectionName: collectionName, Query: query}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool { return results[a].Distance < results[b].Distance })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func copyDocument(document models.ChromaDocument) models.ChromaDocument {
+	document.Metadata = copyMetadata(document.Metadata)
+	return document
+}
+
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,258 @@
+package dbmigration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	ErrUnsupportedDialect = errors.New("error migrations not available for database")
+	ErrInvalidMigration   = errors.New("error invalid migration file")
+	ErrSchemaOutdated     = errors.New("error database schema is outdated")
+)
+
+// Migration is one schema version, Up and Down are the statements of its sql files
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// MigrationStatus is a migration with the time it was applied, nil when pending
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type IMigrator interface {
+	// Up applies every pending migration in order and returns the applied ones
+	Up(ctx context.Context) ([]Migration, error)
+	// Down reverts the last steps applied migrations and returns the reverted ones
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	// CheckVersion fails with ErrSchemaOutdated while a migration is pending
+	CheckVersion(ctx context.Context) error
+}
+
+type migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations embedded for the dialect of db, migrations/<dialect>
+// holds <version>_<name>.up.sql and <version>_<name>.down.sql files.
+func NewMigrator(db *gorm.DB) (IMigrator, error) {
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.run(ctx, migration.Up, func(tx *gorm.DB) error {
+			return tx.Create(&dao.SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for idx := len(m.migrations) - 1; idx >= 0 && len(done) < steps; idx-- {
+		migration := m.migrations[idx]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := m.run(ctx, migration.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&dao.SchemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to revert migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func (m *migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *migrator) CheckVersion(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w, pending migrations %s, run migrate up", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// applied returns the schema_migrations rows by version, a database without the table has
+// nothing applied. With create the table is made when missing.
+func (m *migrator) applied(ctx context.Context, create bool) (map[int]dao.SchemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&dao.SchemaMigration{}) {
+		if !create {
+			return map[int]dao.SchemaMigration{}, nil
+		}
+		if err := db.Migrator().CreateTable(&dao.SchemaMigration{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var records []dao.SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]dao.SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// run executes the statements and the bookkeeping in one transaction. PostgreSQL rolls a
+// failed migration back entirely. MySQL commits every DDL statement on its own, the
+// transaction does not make a migration atomic there: the statements that ran before
+// the failure stay and the version is not recorded. The MySQL files create tables with
+// IF NOT EXISTS and change a table in a single ALTER TABLE, so fixing the cause and
+// running up again continues where it stopped.
+func (m *migrator) run(ctx context.Context, statements []string, record func(tx *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return record(tx)
+	})
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedDialect, dialect)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || !strings.HasSuffix(name, ".sql") || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("%w %s", ErrInvalidMigration, name)
+		}
+		number, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%w %s", ErrInvalidMigration, name)
+		}
+
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: label}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = splitStatements(string(content))
+		} else {
+			migration.Down = splitStatements(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil || migration.Down == nil {
+			return nil, fmt.Errorf("%w %04d_%s needs an up and a down file", ErrInvalidMigration, migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(a, b int) bool { return migrations[a].Version < migrations[b].Version })
+
+	return migrations, nil
+}
+
+// splitStatements cuts a file on the semicolons ending a line, the drivers run one
+// statement per call.
+func splitStatements(content string) []string {
+	statements := []string{}
+	for _, statement := range strings.Split(content, ";\n") {
+		statement = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(statement), ";"))
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
@@ -0,0 +1,24 @@
+ALTER TABLE cv_evaluator_job
+    DROP INDEX idx_cv_evaluator_job_low_confidence,
+    DROP INDEX idx_cv_evaluator_job_bias_flagged,
+    DROP COLUMN feedback_verification,
+    DROP COLUMN score_spread,
+    DROP COLUMN generation_settings,
+    DROP COLUMN `usage`,
+    DROP COLUMN bias_check,
+    DROP COLUMN trace,
+    DROP COLUMN ocr_summary,
+    DROP COLUMN no_cache,
+    DROP COLUMN low_confidence,
+    DROP COLUMN bias_flagged,
+    MODIFY COLUMN status ENUM('queued', 'processing', 'completed', 'failed'),
+    DROP COLUMN rubric_version,
+    DROP COLUMN prompt_version,
+    DROP COLUMN model,
+    DROP COLUMN parent_job_id;
+
+DROP TABLE IF EXISTS vector_record;
+DROP TABLE IF EXISTS llm_cache;
+DROP TABLE IF EXISTS llm_rate_window;
+DROP TABLE IF EXISTS llm_usage;
+DROP TABLE IF EXISTS candidate_profile;
@@ -0,0 +1,76 @@
+CREATE TABLE IF NOT EXISTS candidate_profile (
+    id                  INT AUTO_INCREMENT PRIMARY KEY,
+    job_id              VARCHAR(50),
+    file_id             VARCHAR(50),
+    name                VARCHAR(255),
+    email               VARCHAR(255),
+    phone               VARCHAR(50),
+    location            VARCHAR(255),
+    years_of_experience DOUBLE,
+    employment          TEXT,
+    education           TEXT,
+    skills              TEXT,
+    certifications      TEXT,
+    links               TEXT,
+    created_at          DATETIME(3),
+    updated_at          DATETIME(3),
+    UNIQUE INDEX idx_candidate_profile_job_id (job_id)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id            INT AUTO_INCREMENT PRIMARY KEY,
+    job_id        VARCHAR(50),
+    job_title     VARCHAR(255),
+    model         VARCHAR(100),
+    prompt_tokens INT,
+    output_tokens INT,
+    total_tokens  INT,
+    cost_usd      DOUBLE,
+    created_at    DATETIME(3),
+    INDEX idx_llm_usage_job_id (job_id),
+    INDEX idx_llm_usage_model (model),
+    INDEX idx_llm_usage_created_at (created_at)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS llm_rate_window (
+    limiter_key  VARCHAR(100),
+    window_start DATETIME(3),
+    requests     INT,
+    tokens       INT,
+    PRIMARY KEY (limiter_key, window_start)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS llm_cache (
+    cache_key  VARCHAR(64) PRIMARY KEY,
+    response   LONGTEXT,
+    expires_at DATETIME(3),
+    INDEX idx_llm_cache_expires_at (expires_at)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS vector_record (
+    collection_name VARCHAR(191),
+    record_id       VARCHAR(191),
+    content         LONGTEXT,
+    metadata        TEXT,
+    embedding       BLOB,
+    PRIMARY KEY (collection_name, record_id)
+) DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE cv_evaluator_job
+    ADD COLUMN parent_job_id VARCHAR(50),
+    ADD COLUMN model VARCHAR(100),
+    ADD COLUMN prompt_version VARCHAR(20),
+    ADD COLUMN rubric_version VARCHAR(20),
+    MODIFY COLUMN status VARCHAR(20),
+    ADD COLUMN bias_flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN low_confidence BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN no_cache BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN ocr_summary TEXT,
+    ADD COLUMN trace LONGTEXT,
+    ADD COLUMN bias_check TEXT,
+    ADD COLUMN `usage` TEXT,
+    ADD COLUMN generation_settings TEXT,
+    ADD COLUMN score_spread TEXT,
+    ADD COLUMN feedback_verification TEXT,
+    ADD INDEX idx_cv_evaluator_job_bias_flagged (bias_flagged),
+    ADD INDEX idx_cv_evaluator_job_low_confidence (low_confidence);
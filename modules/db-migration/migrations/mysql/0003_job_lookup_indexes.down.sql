@@ -0,0 +1,2 @@
+DROP INDEX idx_cv_evaluator_job_file_id ON cv_evaluator_job;
+DROP INDEX idx_cv_evaluator_job_job_id ON cv_evaluator_job;
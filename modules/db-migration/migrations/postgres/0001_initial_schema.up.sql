@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS cv_evaluator_job (
+    id               SERIAL PRIMARY KEY,
+    file_id          VARCHAR(50),
+    job_id           VARCHAR(50),
+    job_title        TEXT,
+    status           VARCHAR(20),
+    cv_match_rate    VARCHAR(10),
+    cv_feedback      TEXT,
+    project_score    VARCHAR(10),
+    project_feedback TEXT,
+    overall_summary  TEXT
+);
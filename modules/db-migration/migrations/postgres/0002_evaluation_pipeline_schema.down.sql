@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS llm_cache;
+DROP TABLE IF EXISTS llm_rate_window;
+DROP TABLE IF EXISTS llm_usage;
+DROP TABLE IF EXISTS candidate_profile;
+
+ALTER TABLE cv_evaluator_job
+    DROP COLUMN IF EXISTS feedback_verification,
+    DROP COLUMN IF EXISTS score_spread,
+    DROP COLUMN IF EXISTS generation_settings,
+    DROP COLUMN IF EXISTS "usage",
+    DROP COLUMN IF EXISTS bias_check,
+    DROP COLUMN IF EXISTS trace,
+    DROP COLUMN IF EXISTS ocr_summary,
+    DROP COLUMN IF EXISTS no_cache,
+    DROP COLUMN IF EXISTS low_confidence,
+    DROP COLUMN IF EXISTS bias_flagged,
+    DROP COLUMN IF EXISTS rubric_version,
+    DROP COLUMN IF EXISTS prompt_version,
+    DROP COLUMN IF EXISTS model,
+    DROP COLUMN IF EXISTS parent_job_id;
@@ -0,0 +1,65 @@
+ALTER TABLE cv_evaluator_job
+    ADD COLUMN IF NOT EXISTS parent_job_id VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS model VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS prompt_version VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS rubric_version VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS bias_flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS low_confidence BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS no_cache BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS ocr_summary TEXT,
+    ADD COLUMN IF NOT EXISTS trace TEXT,
+    ADD COLUMN IF NOT EXISTS bias_check TEXT,
+    ADD COLUMN IF NOT EXISTS "usage" TEXT,
+    ADD COLUMN IF NOT EXISTS generation_settings TEXT,
+    ADD COLUMN IF NOT EXISTS score_spread TEXT,
+    ADD COLUMN IF NOT EXISTS feedback_verification TEXT;
+CREATE INDEX IF NOT EXISTS idx_cv_evaluator_job_bias_flagged ON cv_evaluator_job (bias_flagged);
+CREATE INDEX IF NOT EXISTS idx_cv_evaluator_job_low_confidence ON cv_evaluator_job (low_confidence);
+
+CREATE TABLE IF NOT EXISTS candidate_profile (
+    id                  SERIAL PRIMARY KEY,
+    job_id              VARCHAR(50) UNIQUE,
+    file_id             VARCHAR(50),
+    name                VARCHAR(255),
+    email               VARCHAR(255),
+    phone               VARCHAR(50),
+    location            VARCHAR(255),
+    years_of_experience DOUBLE PRECISION,
+    employment          TEXT,
+    education           TEXT,
+    skills              TEXT,
+    certifications      TEXT,
+    links               TEXT,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id            SERIAL PRIMARY KEY,
+    job_id        VARCHAR(50),
+    job_title     VARCHAR(255),
+    model         VARCHAR(100),
+    prompt_tokens INTEGER,
+    output_tokens INTEGER,
+    total_tokens  INTEGER,
+    cost_usd      DOUBLE PRECISION,
+    created_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_job_id ON llm_usage (job_id);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_model ON llm_usage (model);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_created_at ON llm_usage (created_at);
+
+CREATE TABLE IF NOT EXISTS llm_rate_window (
+    limiter_key  VARCHAR(100),
+    window_start TIMESTAMPTZ,
+    requests     INTEGER,
+    tokens       INTEGER,
+    PRIMARY KEY (limiter_key, window_start)
+);
+
+CREATE TABLE IF NOT EXISTS llm_cache (
+    cache_key  VARCHAR(64) PRIMARY KEY,
+    response   TEXT,
+    expires_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_llm_cache_expires_at ON llm_cache (expires_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cv_evaluator_job_file_id;
+DROP INDEX IF EXISTS idx_cv_evaluator_job_job_id;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_cv_evaluator_job_job_id ON cv_evaluator_job (job_id);
+CREATE INDEX IF NOT EXISTS idx_cv_evaluator_job_file_id ON cv_evaluator_job (file_id);
@@ -0,0 +1,122 @@
+package geminiclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+// CacheKey names the inputs of a call for the response cache, model, generation settings
+// and the prompt itself are added by the client.
+type CacheKey struct {
+	Stage         string
+	PromptVersion string
+	ChunkIds      []string
+	CandidateHash string
+	// Variant separates calls with the same inputs that must not share a response, e.g.
+	// the samples of a self-consistency run
+	Variant string
+}
+
+// CacheHitRecorder is called when a response is served from the cache
+type CacheHitRecorder func(ctx context.Context, stage string)
+
+// WithCacheKey makes the call cacheable, calls without a key always reach the model
+func WithCacheKey(key CacheKey) GenerateOption {
+	return func(c *generateConfig) {
+		c.cacheKey = &key
+	}
+}
+
+// WithCacheVariant sets the variant of the cache key given before it
+func WithCacheVariant(variant string) GenerateOption {
+	return func(c *generateConfig) {
+		if c.cacheKey != nil {
+			key := *c.cacheKey
+			key.Variant = variant
+			c.cacheKey = &key
+		}
+	}
+}
+
+// WithCacheBypass skips the cache lookup, the fresh response still replaces the entry
+func WithCacheBypass(bypass bool) GenerateOption {
+	return func(c *generateConfig) {
+		c.cacheBypass = bypass
+	}
+}
+
+func WithCacheHitRecorder(recorder CacheHitRecorder) GenerateOption {
+	return func(c *generateConfig) {
+		c.cacheHitRecorder = recorder
+	}
+}
+
+// HashText is the hex SHA-256 of text, used for the candidate hash of a cache key
+func HashText(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheKey hashes everything that decides the response, an empty key means the call is
+// not cached.
+func (g *geminiClient) cacheKey(jobTitle, prompt string, cfg *generateConfig) string {
+	if g.cache == nil || cfg.cacheKey == nil {
+		return ""
+	}
+
+	raw, err := json.Marshal(struct {
+		Key              CacheKey                  `json:"key"`
+		Model            string                    `json:"model"`
+		Settings         models.GenerationSettings `json:"settings"`
+		ResponseMIMEType string                    `json:"response_mime_type"`
+		JobTitle         string                    `json:"job_title"`
+		PromptHash       string                    `json:"prompt_hash"`
+	}{
+		Key:              *cfg.cacheKey,
+		Model:            cfg.model,
+		Settings:         cfg.settings,
+		ResponseMIMEType: cfg.responseMIMEType,
+		JobTitle:         jobTitle,
+		PromptHash:       HashText(prompt),
+	})
+	if err != nil {
+		return ""
+	}
+
+	return HashText(string(raw))
+}
+
+// cachedResponse never fails the call, a broken cache only costs a model call.
+func (g *geminiClient) cachedResponse(ctx context.Context, key string, cfg *generateConfig) (string, bool) {
+	if key == "" || cfg.cacheBypass {
+		return "", false
+	}
+
+	response, ok, err := g.cache.Get(ctx, key)
+	if err != nil {
+		log.Printf("llm cache lookup failed: %s", err.Error())
+		return "", false
+	}
+	if !ok {
+		return "", false
+	}
+
+	if cfg.cacheHitRecorder != nil {
+		cfg.cacheHitRecorder(ctx, cfg.cacheKey.Stage)
+	}
+	return response, true
+}
+
+func (g *geminiClient) storeResponse(ctx context.Context, key, response string) {
+	if key == "" || response == "" {
+		return
+	}
+	if err := g.cache.Set(ctx, key, response, g.cacheTTL); err != nil {
+		log.Printf("llm cache store failed: %s", err.Error())
+	}
+}
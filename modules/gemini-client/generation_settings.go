@@ -0,0 +1,62 @@
+package geminiclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+)
+
+// DefaultGenerationSettings are used for every parameter a stage does not configure, the
+// model stays empty so the client or job model applies.
+func DefaultGenerationSettings() models.GenerationSettings {
+	return models.GenerationSettings{
+		Temperature:     0.9,
+		TopP:            0.9,
+		TopK:            40,
+		MaxOutputTokens: maxOutputTokens,
+	}
+}
+
+// ParseGenerationSettings reads "key=value" pairs separated by commas over base, keys are
+// model, temperature, top_p, top_k and max_output_tokens.
+func ParseGenerationSettings(value string, base models.GenerationSettings) (models.GenerationSettings, error) {
+	settings := base
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok {
+			return base, fmt.Errorf("invalid generation setting %q", pair)
+		}
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+
+		if key == "model" {
+			settings.Model = raw
+			continue
+		}
+
+		number, err := strconv.ParseFloat(raw, 32)
+		if err != nil {
+			return base, fmt.Errorf("invalid value for generation setting %s: %s", key, raw)
+		}
+		switch key {
+		case "temperature":
+			settings.Temperature = float32(number)
+		case "top_p":
+			settings.TopP = float32(number)
+		case "top_k":
+			settings.TopK = float32(number)
+		case "max_output_tokens":
+			settings.MaxOutputTokens = int32(number)
+		default:
+			return base, fmt.Errorf("unknown generation setting %s", key)
+		}
+	}
+
+	return settings, nil
+}
@@ -3,53 +3,240 @@ package geminiclient
 import (
 	"context"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	llmcache "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/llm-cache"
+	ratelimiter "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/rate-limiter"
 	"google.golang.org/genai"
 )
 
+const (
+	maxOutputTokens = 4096
+	// estimatedOutputTokens is reserved in the tokens per minute budget before the call,
+	// the real usage replaces it afterwards
+	estimatedOutputTokens = 1024
+)
+
 type IGeminiClient interface {
-	GenerateContent(ctx context.Context, jobTitle, prompt string) (string, error)
+	GenerateContent(ctx context.Context, jobTitle, prompt string, opts ...GenerateOption) (string, error)
 }
 
+type GenerateOption func(*generateConfig)
+
+type generateConfig struct {
+	model            string
+	settings         models.GenerationSettings
+	responseMIMEType string
+	usageRecorder    UsageRecorder
+	cacheKey         *CacheKey
+	cacheBypass      bool
+	cacheHitRecorder CacheHitRecorder
+}
+
+// Options
+func WithModel(model string) GenerateOption {
+	return func(c *generateConfig) {
+		if model != "" {
+			c.model = model
+		}
+	}
+}
+
+// WithGenerationSettings replaces the sampling parameters, a non-empty model also
+// replaces the model
+func WithGenerationSettings(settings models.GenerationSettings) GenerateOption {
+	return func(c *generateConfig) {
+		if settings.Model != "" {
+			c.model = settings.Model
+		}
+		c.settings = settings
+	}
+}
+
+// WithResponseMIMEType asks the model for a specific output format, e.g. application/json
+func WithResponseMIMEType(mimeType string) GenerateOption {
+	return func(c *generateConfig) {
+		c.responseMIMEType = mimeType
+	}
+}
+
+// WithUsageRecorder is called with the token usage of every successful call
+func WithUsageRecorder(recorder UsageRecorder) GenerateOption {
+	return func(c *generateConfig) {
+		c.usageRecorder = recorder
+	}
+}
+
+type ClientOption func(*geminiClient)
+
 type geminiClient struct {
-	cli   *genai.Client
-	model string
+	cli     *genai.Client
+	model   string
+	limiter ratelimiter.ILimiter
+	breaker *ratelimiter.CircuitBreaker
+	retry   RetryPolicy
+
+	cache    llmcache.ICacheStore
+	cacheTTL time.Duration
 }
 
-func NewGeminiAiCLient(ctx context.Context, apiKey, model string) (IGeminiClient, error) {
+func NewGeminiAiCLient(ctx context.Context, apiKey, model string, opts ...ClientOption) (IGeminiClient, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey, Backend: genai.BackendGeminiAPI})
 	if err != nil {
 		return nil, err
 	}
 
-	return &geminiClient{cli: client, model: model}, nil
+	gemini := &geminiClient{cli: client, model: model, retry: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(gemini)
+	}
+
+	return gemini, nil
+}
+
+// Client Options
+func WithRateLimiter(limiter ratelimiter.ILimiter) ClientOption {
+	return func(g *geminiClient) {
+		g.limiter = limiter
+	}
+}
+
+func WithCircuitBreaker(breaker *ratelimiter.CircuitBreaker) ClientOption {
+	return func(g *geminiClient) {
+		g.breaker = breaker
+	}
 }
 
-func (g *geminiClient) GenerateContent(ctx context.Context, jobTitle, prompt string) (string, error) {
+// WithRetryPolicy retries 429 and 5xx responses, MaxAttempts 1 disables retries
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(g *geminiClient) {
+		if policy.MaxAttempts > 0 {
+			g.retry.MaxAttempts = policy.MaxAttempts
+		}
+		if policy.BaseDelay > 0 {
+			g.retry.BaseDelay = policy.BaseDelay
+		}
+		if policy.MaxDelay > 0 {
+			g.retry.MaxDelay = policy.MaxDelay
+		}
+	}
+}
+
+// WithResponseCache serves calls made with a cache key from store, responses are kept
+// for ttl.
+func WithResponseCache(store llmcache.ICacheStore, ttl time.Duration) ClientOption {
+	return func(g *geminiClient) {
+		g.cache = store
+		g.cacheTTL = ttl
+	}
+}
+
+func (g *geminiClient) GenerateContent(ctx context.Context, jobTitle, prompt string, opts ...GenerateOption) (string, error) {
+	generateCfg := &generateConfig{model: g.model, settings: DefaultGenerationSettings()}
+	for _, opt := range opts {
+		opt(generateCfg)
+	}
+
+	cacheKey := g.cacheKey(jobTitle, prompt, generateCfg)
+	if response, ok := g.cachedResponse(ctx, cacheKey, generateCfg); ok {
+		return response, nil
+	}
+
 	systemInstruction := fmt.Sprintf("You are the head recruiter on company and want to evaluate CV and Project for role %s", jobTitle)
-	temp := float32(0.9)
-	topP := float32(0.9)
-	topK := float32(40.0)
-	maxOutputToken := int32(4096)
+	temp := generateCfg.settings.Temperature
+	topP := generateCfg.settings.TopP
+	topK := generateCfg.settings.TopK
 
 	config := &genai.GenerateContentConfig{
 		TopP:              &topP,
 		TopK:              &topK,
 		Temperature:       &temp,
-		MaxOutputTokens:   maxOutputToken,
+		MaxOutputTokens:   generateCfg.settings.MaxOutputTokens,
 		SystemInstruction: genai.NewContentFromText(systemInstruction, genai.RoleModel),
+		ResponseMIMEType:  generateCfg.responseMIMEType,
 	}
 
-	resp, err := g.cli.Models.GenerateContent(
-		ctx,
-		g.model,
-		genai.Text(prompt),
-		config,
-	)
-
+	resp, err := g.generateWithRetry(ctx, generateCfg.model, prompt, config)
 	if err != nil {
 		return "", err
 	}
 
-	return resp.Text(), nil
+	if generateCfg.usageRecorder != nil && resp.UsageMetadata != nil {
+		generateCfg.usageRecorder(ctx, Usage{
+			Model:        generateCfg.model,
+			PromptTokens: int(resp.UsageMetadata.PromptTokenCount),
+			// thinking tokens are billed as output
+			OutputTokens: int(resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount),
+			TotalTokens:  int(resp.UsageMetadata.TotalTokenCount),
+		})
+	}
+
+	text := resp.Text()
+	g.storeResponse(ctx, cacheKey, text)
+	return text, nil
+}
+
+// releaseBreaker ends a call that got no answer without counting it either way, a
+// half-open circuit would otherwise wait forever for the verdict of its trial call.
+func (g *geminiClient) releaseBreaker() {
+	if g.breaker != nil {
+		g.breaker.Release()
+	}
+}
+
+// generateWithRetry waits for the rate limiter before every attempt and retries provider
+// failures with backoff, failures also count towards opening the circuit breaker.
+func (g *geminiClient) generateWithRetry(ctx context.Context, model, prompt string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	estimated := len(prompt)/4 + estimatedOutputTokens
+
+	for attempt := 1; ; attempt++ {
+		if g.breaker != nil {
+			if err := g.breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+		if g.limiter != nil {
+			if err := g.limiter.Wait(ctx, estimated); err != nil {
+				g.releaseBreaker()
+				return nil, err
+			}
+		}
+
+		resp, err := g.cli.Models.GenerateContent(ctx, model, genai.Text(prompt), config)
+		if err == nil {
+			if g.breaker != nil {
+				g.breaker.Success()
+			}
+			if g.limiter != nil && resp.UsageMetadata != nil {
+				g.limiter.Adjust(ctx, int(resp.UsageMetadata.TotalTokenCount)-estimated)
+			}
+			return resp, nil
+		}
+
+		if ctx.Err() != nil {
+			g.releaseBreaker()
+			return nil, err
+		}
+		if !isProviderFailure(err) {
+			// the provider answered, only the request was wrong
+			if g.breaker != nil {
+				g.breaker.Success()
+			}
+			return nil, err
+		}
+		if g.breaker != nil {
+			g.breaker.Failure()
+		}
+		if attempt >= g.retry.MaxAttempts {
+			return nil, err
+		}
+
+		delay := g.retry.backoff(attempt, err)
+		log.Printf("gemini call failed (attempt %d of %d), retrying in %s: %s", attempt, g.retry.MaxAttempts, delay.Round(time.Millisecond), err.Error())
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
 }
@@ -0,0 +1,117 @@
+package geminiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var ErrFixtureNotFound = errors.New("error recorded response not found")
+
+// fixture holds the responses recorded for one prompt, in call order
+type fixture struct {
+	PromptHash string   `json:"prompt_hash"`
+	Responses  []string `json:"responses"`
+}
+
+// FixtureKey is the file name of the recorded responses of a prompt
+func FixtureKey(jobTitle, prompt string) string {
+	return HashText(jobTitle + "\n" + prompt)
+}
+
+type replayClient struct {
+	dir   string
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// NewReplayClient answers every call with the responses recorded in dir for the same
+// prompt, a prompt asked again gets the recorded responses in turn. Options are ignored.
+func NewReplayClient(dir string) IGeminiClient {
+	return &replayClient{dir: dir, calls: make(map[string]int)}
+}
+
+func (r *replayClient) GenerateContent(ctx context.Context, jobTitle, prompt string, opts ...GenerateOption) (string, error) {
+	key := FixtureKey(jobTitle, prompt)
+	recorded, err := readFixture(r.dir, key)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrFixtureNotFound, key)
+	}
+
+	r.mu.Lock()
+	call := r.calls[key]
+	r.calls[key]++
+	r.mu.Unlock()
+
+	return recorded.Responses[call%len(recorded.Responses)], nil
+}
+
+type recordingClient struct {
+	inner    IGeminiClient
+	dir      string
+	mu       sync.Mutex
+	recorded map[string]*fixture
+}
+
+// NewRecordingClient sends every call to inner and writes the responses to dir for
+// NewReplayClient, a fixture recorded by an earlier run is replaced.
+func NewRecordingClient(inner IGeminiClient, dir string) (IGeminiClient, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &recordingClient{inner: inner, dir: dir, recorded: make(map[string]*fixture)}, nil
+}
+
+func (r *recordingClient) GenerateContent(ctx context.Context, jobTitle, prompt string, opts ...GenerateOption) (string, error) {
+	resp, err := r.inner.GenerateContent(ctx, jobTitle, prompt, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	key := FixtureKey(jobTitle, prompt)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recorded, ok := r.recorded[key]
+	if !ok {
+		recorded = &fixture{PromptHash: key}
+		r.recorded[key] = recorded
+	}
+	recorded.Responses = append(recorded.Responses, resp)
+
+	if err := writeFixture(r.dir, recorded); err != nil {
+		return "", err
+	}
+	return resp, nil
+}
+
+func readFixture(dir, key string) (*fixture, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded fixture
+	if err := json.Unmarshal(raw, &recorded); err != nil {
+		return nil, err
+	}
+	if len(recorded.Responses) == 0 {
+		return nil, ErrFixtureNotFound
+	}
+
+	return &recorded, nil
+}
+
+func writeFixture(dir string, recorded *fixture) error {
+	raw, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, recorded.PromptHash+".json"), raw, 0o644)
+}
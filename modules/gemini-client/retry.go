@@ -0,0 +1,90 @@
+package geminiclient
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   2 * time.Second,
+		MaxDelay:    time.Minute,
+	}
+}
+
+var retryInMessage = regexp.MustCompile(`(?i)retry in ([0-9.]+)s`)
+
+// isProviderFailure reports errors caused by the provider being busy or down, 429, 5xx
+// and transport errors, the ones worth a retry and counted by the circuit breaker.
+func isProviderFailure(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+}
+
+// retryHint reads the delay the server asked for, from the google.rpc.RetryInfo detail or
+// the "retry in Ns" message of quota errors.
+func retryHint(err error) time.Duration {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return 0
+	}
+
+	for _, detail := range apiErr.Details {
+		delay, ok := detail["retryDelay"].(string)
+		if !ok {
+			continue
+		}
+		if parsed, err := time.ParseDuration(delay); err == nil {
+			return parsed
+		}
+	}
+
+	if match := retryInMessage.FindStringSubmatch(apiErr.Message); match != nil {
+		if seconds, err := strconv.ParseFloat(match[1], 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// backoff doubles the base delay per attempt with up to 20% jitter, a server hint wins.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	if hint := retryHint(err); hint > 0 {
+		return min(hint, p.MaxDelay)
+	}
+
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay + time.Duration(rand.Float64()*0.2*float64(delay))
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package geminiclient
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+)
+
+type Usage struct {
+	Model        string
+	PromptTokens int
+	OutputTokens int
+	TotalTokens  int
+}
+
+type UsageRecorder func(ctx context.Context, usage Usage)
+
+// ModelPrice is in USD per million tokens
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+type PriceTable map[string]ModelPrice
+
+// ParsePriceTable reads "model:input:output" entries separated by commas, prices are in
+// USD per million tokens, e.g. "gemini-2.5-flash:0.30:2.50".
+func ParsePriceTable(value string) PriceTable {
+	prices := make(PriceTable)
+	for _, entry := range strings.Split(value, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ":")
+		if len(fields) != 3 {
+			continue
+		}
+
+		input, inputErr := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		output, outputErr := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		if inputErr != nil || outputErr != nil {
+			log.Printf("invalid price for model %s", fields[0])
+			continue
+		}
+		prices[strings.TrimSpace(fields[0])] = ModelPrice{Input: input, Output: output}
+	}
+	return prices
+}
+
+// Cost prices the usage with the longest model name in the table that prefixes the used
+// model, so "gemini-2.5-flash" also prices "gemini-2.5-flash-001". Unknown models cost 0.
+func (p PriceTable) Cost(usage Usage) float64 {
+	matched := ""
+	for model := range p {
+		if strings.HasPrefix(usage.Model, model) && len(model) > len(matched) {
+			matched = model
+		}
+	}
+	if matched == "" {
+		return 0
+	}
+
+	price := p[matched]
+	return (float64(usage.PromptTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1_000_000
+}
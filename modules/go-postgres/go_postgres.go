@@ -0,0 +1,45 @@
+package gopostgres
+
+import (
+	"net/url"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type PostgresConfig struct {
+	DBUser     string
+	DBPassword string
+	DBHost     string
+	DBPort     string
+	DBName     string
+	// SslMode is the libpq sslmode, defaults to disable
+	SslMode string
+}
+
+// Dsn builds the connection URL, the credentials are escaped so any password works.
+func Dsn(config *PostgresConfig) string {
+	sslMode := config.SslMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     config.DBHost + ":" + config.DBPort,
+		Path:     "/" + config.DBName,
+		RawQuery: url.Values{"sslmode": {sslMode}, "TimeZone": {"UTC"}}.Encode(),
+	}
+	return dsn.String()
+}
+
+func NewDatabaseConnection(config *PostgresConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(Dsn(config)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
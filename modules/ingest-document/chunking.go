@@ -0,0 +1,280 @@
+package ingestdocument
+
+import (
+	"regexp"
+	"strings"
+)
+
+type ChunkStrategy string
+
+const (
+	// ChunkByWords slides a fixed window over the words, the original strategy
+	ChunkByWords ChunkStrategy = "words"
+	// ChunkBySentence packs whole sentences and list rows into each chunk
+	ChunkBySentence ChunkStrategy = "sentence"
+	// ChunkByMarkdown splits on headings and prefixes every chunk with its section title
+	ChunkByMarkdown ChunkStrategy = "markdown"
+)
+
+type textChunk struct {
+	text    string
+	section string
+}
+
+var (
+	sentenceEnd    = regexp.MustCompile(`([.!?])\s+`)
+	markdownHeader = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+)
+
+// sanitize fills the defaults, sizes are in words unless MaxTokens is set.
+func (c ChunkingConfig) sanitize() ChunkingConfig {
+	switch c.Strategy {
+	case ChunkBySentence, ChunkByMarkdown:
+	default:
+		c.Strategy = ChunkByWords
+	}
+
+	if c.MaxTokens > 0 {
+		if c.Tokenizer == nil {
+			c.Tokenizer = NewApproxTokenizer()
+		}
+		if c.OverlapTokens < 0 {
+			c.OverlapTokens = 0
+		}
+		if c.OverlapTokens >= c.MaxTokens {
+			c.OverlapTokens = c.MaxTokens / 2
+		}
+		return c
+	}
+
+	c.Tokenizer = NewWordTokenizer()
+	if c.WordsPerChunk <= 0 {
+		c.WordsPerChunk = 150
+	}
+	if c.OverlapWords < 0 {
+		c.OverlapWords = 0
+	}
+	if c.OverlapWords >= c.WordsPerChunk {
+		c.OverlapWords = c.WordsPerChunk / 2
+	}
+	c.MaxTokens = c.WordsPerChunk
+	c.OverlapTokens = c.OverlapWords
+	return c
+}
+
+func chunkDocument(text string, config ChunkingConfig) []textChunk {
+	config = config.sanitize()
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return []textChunk{}
+	}
+
+	switch config.Strategy {
+	case ChunkBySentence:
+		return toChunks(packUnits(splitSentences(text), config, config.MaxTokens), "")
+	case ChunkByMarkdown:
+		return chunkMarkdown(text, config)
+	default:
+		return toChunks(chunkWords(strings.Fields(text), config, config.MaxTokens), "")
+	}
+}
+
+// chunkWords slides over the words, each chunk holds up to limit tokens and starts
+// with the overlap of the previous one.
+func chunkWords(words []string, config ChunkingConfig, limit int) []string {
+	if len(words) == 0 {
+		return nil
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(words) {
+		end := start
+		size := 0
+		for end < len(words) {
+			wordSize := config.Tokenizer.CountTokens(words[end])
+			if end > start && size+wordSize > limit {
+				break
+			}
+			size += wordSize
+			end++
+		}
+
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+
+		// step back over the overlap but always move forward
+		next := end
+		overlap := 0
+		for next-1 > start {
+			wordSize := config.Tokenizer.CountTokens(words[next-1])
+			if overlap+wordSize > config.OverlapTokens {
+				break
+			}
+			overlap += wordSize
+			next--
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// splitSentences breaks on sentence punctuation and on line breaks, rubric rows and
+// bullet items are one unit each.
+func splitSentences(text string) []string {
+	var units []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		marked := sentenceEnd.ReplaceAllString(line, "$1\n")
+		for _, sentence := range strings.Split(marked, "\n") {
+			if sentence = strings.TrimSpace(sentence); sentence != "" {
+				units = append(units, sentence)
+			}
+		}
+	}
+	return units
+}
+
+// packUnits joins whole units up to limit tokens, the trailing units of a chunk that fit
+// in the overlap are repeated at the start of the next one. A unit larger than the
+// limit is split by words.
+func packUnits(units []string, config ChunkingConfig, limit int) []string {
+	if limit <= 0 {
+		limit = 1
+	}
+
+	var chunks []string
+	var current []string
+	size := 0
+	// fresh is false while current only holds the overlap carried from the last chunk
+	fresh := false
+
+	flush := func() {
+		if !fresh {
+			return
+		}
+		chunks = append(chunks, strings.Join(current, " "))
+
+		var carry []string
+		carrySize := 0
+		for idx := len(current) - 1; idx > 0; idx-- {
+			unitSize := config.Tokenizer.CountTokens(current[idx])
+			if carrySize+unitSize > config.OverlapTokens {
+				break
+			}
+			carry = append([]string{current[idx]}, carry...)
+			carrySize += unitSize
+		}
+		current, size, fresh = carry, carrySize, false
+	}
+
+	for _, unit := range units {
+		unitSize := config.Tokenizer.CountTokens(unit)
+		if unitSize > limit {
+			flush()
+			chunks = append(chunks, chunkWords(strings.Fields(unit), config, limit)...)
+			current, size, fresh = nil, 0, false
+			continue
+		}
+
+		if size+unitSize > limit {
+			flush()
+			// the carried overlap must still leave room for the unit
+			for len(current) > 0 && size+unitSize > limit {
+				size -= config.Tokenizer.CountTokens(current[0])
+				current = current[1:]
+			}
+		}
+		current = append(current, unit)
+		size += unitSize
+		fresh = true
+	}
+	flush()
+
+	return chunks
+}
+
+type markdownSection struct {
+	title string
+	body  []string
+}
+
+// chunkMarkdown keeps each heading path, e.g. "Scoring > Technical", attached to the
+// chunks of its section so a chunk retrieved alone still says what it is about.
+func chunkMarkdown(text string, config ChunkingConfig) []textChunk {
+	var sections []markdownSection
+	var headings []string
+	current := markdownSection{}
+
+	for _, line := range strings.Split(text, "\n") {
+		match := markdownHeader.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			current.body = append(current.body, line)
+			continue
+		}
+
+		sections = append(sections, current)
+		level := len(match[1])
+		if level <= len(headings) {
+			headings = headings[:level-1]
+		}
+		for len(headings) < level-1 {
+			headings = append(headings, "")
+		}
+		headings = append(headings, match[2])
+		current = markdownSection{title: joinHeadings(headings)}
+	}
+	sections = append(sections, current)
+
+	var chunks []textChunk
+	for _, section := range sections {
+		units := splitSentences(strings.Join(section.body, "\n"))
+		if len(units) == 0 {
+			continue
+		}
+
+		limit := config.MaxTokens
+		if section.title != "" {
+			limit -= config.Tokenizer.CountTokens(section.title)
+			if limit < config.MaxTokens/2 {
+				limit = config.MaxTokens / 2
+			}
+		}
+
+		for _, body := range packUnits(units, config, limit) {
+			chunk := body
+			if section.title != "" {
+				chunk = section.title + "\n" + body
+			}
+			chunks = append(chunks, textChunk{text: chunk, section: section.title})
+		}
+	}
+
+	return chunks
+}
+
+func joinHeadings(headings []string) string {
+	var parts []string
+	for _, heading := range headings {
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+func toChunks(texts []string, section string) []textChunk {
+	chunks := make([]textChunk, 0, len(texts))
+	for _, text := range texts {
+		chunks = append(chunks, textChunk{text: text, section: section})
+	}
+	return chunks
+}
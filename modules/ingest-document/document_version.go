@@ -0,0 +1,173 @@
+package ingestdocument
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+)
+
+// chunk metadata keys written by IngestToChroma, "version" is what evaluations pin with
+// the rubric version
+const (
+	MetadataVersion       = "version"
+	MetadataVersionNumber = "version_number"
+	MetadataContentHash   = "content_hash"
+	MetadataIngestedAt    = "ingested_at"
+	MetadataLatest        = "latest"
+)
+
+var (
+	ErrIngestVersionSwitch = errors.New("error switch the latest document version")
+	ErrIngestStaleCleanup  = errors.New("error remove chunks of previous document versions")
+)
+
+type documentVersion struct {
+	number int
+	label  string
+	hash   string
+	latest bool
+	chunks []models.ChromaDocument
+}
+
+// contentHash covers the chunking settings too, re-chunking the same text is a change.
+func contentHash(content string, config ChunkingConfig) string {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s|%d|%d|%d|%d|%s\n", config.Strategy, config.WordsPerChunk, config.OverlapWords, config.MaxTokens, config.OverlapTokens, config.Tokenizer.Name())
+	sum.Write([]byte(content))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// documentVersions groups the stored chunks of docId by version, oldest first. Chunks
+// written before versioning have no version number and count as version 0.
+func (i *ingestFile) documentVersions(ctx context.Context, collectionName, docId string) ([]*documentVersion, error) {
+	documents, err := i.chroma.Get(ctx, collectionName, chromaclient.WithWhereEq("document_id", docId))
+	if err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[int]*documentVersion)
+	for _, document := range documents {
+		number, _ := strconv.Atoi(metadataString(document.Metadata, MetadataVersionNumber))
+		version, ok := byNumber[number]
+		if !ok {
+			version = &documentVersion{
+				number: number,
+				label:  metadataString(document.Metadata, MetadataVersion),
+				hash:   metadataString(document.Metadata, MetadataContentHash),
+				latest: metadataString(document.Metadata, MetadataLatest) != "false",
+			}
+			byNumber[number] = version
+		}
+		version.chunks = append(version.chunks, document)
+	}
+
+	versions := make([]*documentVersion, 0, len(byNumber))
+	for _, version := range byNumber {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(a, b int) bool { return versions[a].number < versions[b].number })
+
+	return versions, nil
+}
+
+// retireVersions runs after the new version is stored, its chunks are written as not
+// latest so retrieval keeps using the previous version meanwhile. The new chunks are
+// marked latest and every previous latest version unmarked in the same update request,
+// and only then are the versions beyond RetainVersions, older versions with the same
+// label and leftovers of an ingest that never switched deleted. A failed update keeps
+// the previous version as the latest one, a failed delete only leaves extra versions
+// that are never retrieved unpinned. RetainVersions 0 keeps every version pinnable.
+func (i *ingestFile) retireVersions(ctx context.Context, collectionName string, documents []models.ChromaDocument, previous []*documentVersion, label string, retain int) error {
+	switched := make([]models.ChromaDocument, 0, len(documents))
+	for _, document := range documents {
+		switched = append(switched, withLatest(document, "true"))
+	}
+
+	lastLatest := -1
+	for idx, version := range previous {
+		if version.latest {
+			lastLatest = idx
+		}
+	}
+
+	var staleIds []string
+	kept := 1
+	for idx := len(previous) - 1; idx >= 0; idx-- {
+		version := previous[idx]
+		if version.latest {
+			for _, chunk := range version.chunks {
+				switched = append(switched, withLatest(chunk, "false"))
+			}
+		}
+
+		unswitched := idx > lastLatest
+		if unswitched || (retain > 0 && kept >= retain) || version.label == label || version.number == 0 {
+			for _, chunk := range version.chunks {
+				staleIds = append(staleIds, chunk.Id)
+			}
+			continue
+		}
+		kept++
+	}
+
+	if err := i.chroma.UpdateMetadata(ctx, collectionName, switched); err != nil {
+		return fmt.Errorf("%w: %w", ErrIngestVersionSwitch, err)
+	}
+	if err := i.chroma.Delete(ctx, collectionName, staleIds); err != nil {
+		return fmt.Errorf("%w: %w", ErrIngestStaleCleanup, err)
+	}
+
+	return nil
+}
+
+// BackfillLatest marks the chunks written before versioning as latest, retrieval only
+// searches chunks marked latest.
+func (i *ingestFile) BackfillLatest(ctx context.Context, collectionName string) (int, error) {
+	documents, err := i.chroma.Get(ctx, collectionName)
+	if err != nil {
+		return 0, err
+	}
+
+	var legacy []models.ChromaDocument
+	for _, document := range documents {
+		if _, ok := document.Metadata[MetadataLatest]; !ok {
+			legacy = append(legacy, withLatest(document, "true"))
+		}
+	}
+	if err := i.chroma.UpdateMetadata(ctx, collectionName, legacy); err != nil {
+		return 0, err
+	}
+
+	return len(legacy), nil
+}
+
+// withLatest copies the chunk metadata with the latest flag set, updates replace the
+// whole metadata.
+func withLatest(chunk models.ChromaDocument, latest string) models.ChromaDocument {
+	metadata := make(map[string]interface{}, len(chunk.Metadata)+1)
+	for k, v := range chunk.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataLatest] = latest
+	return models.ChromaDocument{Id: chunk.Id, Metadata: metadata}
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	switch value := metadata[key].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,149 @@
+package ingestdocument
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+)
+
+const testCollection = "cv_rubric"
+
+func latestVersions(t *testing.T, chroma chromaclient.IChromaClient) map[string]bool {
+	t.Helper()
+	results, err := chroma.Query(context.Background(), testCollection, "rubric", 10, chromaclient.WithWhereNotEq(MetadataLatest, "false"))
+	if err != nil {
+		t.Fatalf("query: %s", err.Error())
+	}
+
+	versions := make(map[string]bool)
+	for _, result := range results {
+		versions[metadataString(result.Metadata, MetadataVersion)] = true
+	}
+	return versions
+}
+
+func TestReingestRetrievesOnlyNewVersion(t *testing.T) {
+	ctx := context.Background()
+	chroma := chromaclient.NewMemoryChromaClient()
+	ingest := NewIngestFile(chroma)
+
+	for _, content := range []string{"rubric version one", "rubric version two"} {
+		if err := ingest.IngestToChroma(ctx, testCollection, "rubric", content, map[string]interface{}{}, WithDefaultIngestOptions()); err != nil {
+			t.Fatalf("ingest: %s", err.Error())
+		}
+	}
+
+	versions := latestVersions(t, chroma)
+	if len(versions) != 1 || !versions["2"] {
+		t.Fatalf("latest versions = %v, want only 2", versions)
+	}
+
+	pinned, err := chroma.Get(ctx, testCollection, chromaclient.WithWhereEq(MetadataVersion, "1"))
+	if err != nil {
+		t.Fatalf("get: %s", err.Error())
+	}
+	if len(pinned) == 0 {
+		t.Fatal("version 1 was deleted, want it kept for pinning")
+	}
+}
+
+func TestRetainVersionsDeletesOldest(t *testing.T) {
+	ctx := context.Background()
+	chroma := chromaclient.NewMemoryChromaClient()
+	ingest := NewIngestFile(chroma)
+	options := WithDefaultIngestOptions()
+	options.RetainVersions = 2
+
+	for _, content := range []string{"rubric one", "rubric two", "rubric three"} {
+		if err := ingest.IngestToChroma(ctx, testCollection, "rubric", content, map[string]interface{}{}, options); err != nil {
+			t.Fatalf("ingest: %s", err.Error())
+		}
+	}
+
+	documents, err := chroma.Get(ctx, testCollection)
+	if err != nil {
+		t.Fatalf("get: %s", err.Error())
+	}
+	for _, document := range documents {
+		if metadataString(document.Metadata, MetadataVersion) == "1" {
+			t.Fatalf("version 1 still stored as %s", document.Id)
+		}
+	}
+}
+
+func TestUnswitchedVersionIsNotRetrieved(t *testing.T) {
+	ctx := context.Background()
+	chroma := chromaclient.NewMemoryChromaClient()
+	ingest := NewIngestFile(chroma)
+
+	if err := ingest.IngestToChroma(ctx, testCollection, "rubric", "rubric version one", map[string]interface{}{}, WithDefaultIngestOptions()); err != nil {
+		t.Fatalf("ingest: %s", err.Error())
+	}
+	// chunks of an ingest that stopped before the switch
+	leftover := models.ChromaDocument{
+		Id:      "rubric_v2_chunk_0",
+		Content: "rubric version two",
+		Metadata: map[string]interface{}{
+			"document_id":         "rubric",
+			MetadataVersion:       "2",
+			MetadataVersionNumber: "2",
+			MetadataLatest:        "false",
+		},
+	}
+	if err := chroma.UpsertBatch(ctx, testCollection, []models.ChromaDocument{leftover}); err != nil {
+		t.Fatalf("upsert: %s", err.Error())
+	}
+
+	if versions := latestVersions(t, chroma); len(versions) != 1 || !versions["1"] {
+		t.Fatalf("latest versions = %v, want only 1", versions)
+	}
+
+	// the same content again is still a new version since the leftover never switched
+	if err := ingest.IngestToChroma(ctx, testCollection, "rubric", "rubric version two", map[string]interface{}{}, WithDefaultIngestOptions()); err != nil {
+		t.Fatalf("ingest: %s", err.Error())
+	}
+	if versions := latestVersions(t, chroma); len(versions) != 1 || !versions["3"] {
+		t.Fatalf("latest versions = %v, want only 3", versions)
+	}
+	if leftovers, _ := chroma.Get(ctx, testCollection, chromaclient.WithWhereEq(MetadataVersion, "2")); len(leftovers) != 0 {
+		t.Fatalf("leftover version still stored: %d chunks", len(leftovers))
+	}
+}
+
+func TestBackfillLatestMarksLegacyChunks(t *testing.T) {
+	ctx := context.Background()
+	chroma := chromaclient.NewMemoryChromaClient()
+	ingest := NewIngestFile(chroma)
+
+	legacy := models.ChromaDocument{
+		Id:       "rubric_chunk_0",
+		Content:  "rubric written before versions",
+		Metadata: map[string]interface{}{"document_id": "rubric"},
+	}
+	if err := chroma.UpsertBatch(ctx, testCollection, []models.ChromaDocument{legacy}); err != nil {
+		t.Fatalf("upsert: %s", err.Error())
+	}
+
+	count, err := ingest.BackfillLatest(ctx, testCollection)
+	if err != nil {
+		t.Fatalf("backfill: %s", err.Error())
+	}
+	if count != 1 {
+		t.Fatalf("backfilled %d chunks, want 1", count)
+	}
+
+	documents, err := chroma.Get(ctx, testCollection, chromaclient.WithWhereEq(MetadataLatest, "true"))
+	if err != nil {
+		t.Fatalf("get: %s", err.Error())
+	}
+	if len(documents) != 1 || !strings.Contains(documents[0].Content, "before versions") {
+		t.Fatalf("latest chunks = %v, want the legacy chunk", documents)
+	}
+
+	if count, _ := ingest.BackfillLatest(ctx, testCollection); count != 0 {
+		t.Fatalf("second backfill marked %d chunks, want 0", count)
+	}
+}
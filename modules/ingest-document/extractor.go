@@ -0,0 +1,433 @@
+package ingestdocument
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/net/html"
+)
+
+var (
+	ErrUnsupportedContentType = errors.New("error unsupported content type")
+	ErrReadDocumentFile       = errors.New("error read document file")
+	ErrInvalidDocx            = errors.New("error invalid docx document")
+)
+
+const (
+	ContentTypePdf      = "application/pdf"
+	ContentTypeDocx     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ContentTypeMarkdown = "text/markdown"
+	ContentTypeHtml     = "text/html"
+	ContentTypePlain    = "text/plain"
+	ContentTypeRtf      = "application/rtf"
+	ContentTypeUnknown  = "application/octet-stream"
+)
+
+// extensions per content type, the first one is the canonical extension
+var contentTypeExtensions = map[string][]string{
+	ContentTypePdf:      {".pdf"},
+	ContentTypeDocx:     {".docx"},
+	ContentTypeMarkdown: {".md", ".markdown"},
+	ContentTypeHtml:     {".html", ".htm"},
+	ContentTypePlain:    {".txt", ".text"},
+	ContentTypeRtf:      {".rtf"},
+}
+
+type ITextExtractor interface {
+	Extract(data []byte) (string, error)
+}
+
+type TextExtractorFunc func(data []byte) (string, error)
+
+func (f TextExtractorFunc) Extract(data []byte) (string, error) {
+	return f(data)
+}
+
+type ExtractionResult struct {
+	ContentType string
+	Text        string
+	// OcrPages lists the 1-based pages whose text came from the OCR engine
+	OcrPages      []int
+	OcrEngine     string
+	OcrConfidence float64
+	// Structured is only set by the layout aware PDF extraction
+	Structured *StructuredDocument
+}
+
+// PromptText renders the structured document as Markdown when available, so the LLM
+// sees sections and bullet lists instead of a flattened blob.
+func (r *ExtractionResult) PromptText() string {
+	if r.Structured != nil && len(r.Structured.Sections) > 0 {
+		return r.Structured.Markdown()
+	}
+	return r.Text
+}
+
+func defaultExtractors() map[string]ITextExtractor {
+	return map[string]ITextExtractor{
+		ContentTypePdf:      TextExtractorFunc(extractPdf),
+		ContentTypeDocx:     TextExtractorFunc(extractDocx),
+		ContentTypeMarkdown: TextExtractorFunc(extractMarkdown),
+		ContentTypeHtml:     TextExtractorFunc(extractHtml),
+		ContentTypePlain:    TextExtractorFunc(extractPlainText),
+		ContentTypeRtf:      TextExtractorFunc(extractRtf),
+	}
+}
+
+// ExtractTextFromBytes runs the default extractor of a content type without going
+// through the file system.
+func ExtractTextFromBytes(contentType string, data []byte) (string, error) {
+	extractor, ok := defaultExtractors()[contentType]
+	if !ok {
+		return "", ErrUnsupportedContentType
+	}
+	return extractor.Extract(data)
+}
+
+// SniffContentType detects the document type from its content, the filename is only
+// used to tell apart text based formats that share the same bytes.
+func SniffContentType(data []byte, filename string) string {
+	head := bytes.TrimLeft(data, "\x00\t\r\n \xef\xbb\xbf")
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return ContentTypePdf
+	case bytes.HasPrefix(head, []byte("{\\rtf")):
+		return ContentTypeRtf
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		if isDocx(data) {
+			return ContentTypeDocx
+		}
+		return ContentTypeUnknown
+	}
+
+	if !utf8.Valid(data) {
+		return ContentTypeUnknown
+	}
+
+	if strings.HasPrefix(http.DetectContentType(data), ContentTypeHtml) || ext == ".html" || ext == ".htm" {
+		return ContentTypeHtml
+	}
+	if ext == ".md" || ext == ".markdown" {
+		return ContentTypeMarkdown
+	}
+
+	return ContentTypePlain
+}
+
+func IsSupportedContentType(contentType string) bool {
+	_, ok := contentTypeExtensions[contentType]
+	return ok
+}
+
+// ExtensionForContentType keeps the original extension when it belongs to the detected
+// content type, otherwise it falls back to the canonical extension.
+func ExtensionForContentType(contentType, originalName string) string {
+	extensions, ok := contentTypeExtensions[contentType]
+	if !ok {
+		return ""
+	}
+
+	ext := strings.ToLower(filepath.Ext(originalName))
+	for _, e := range extensions {
+		if e == ext {
+			return ext
+		}
+	}
+	return extensions[0]
+}
+
+func isDocx(data []byte) bool {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+func extractPdf(data []byte) (text string, err error) {
+	// the pdf reader panics on some malformed documents
+	defer func() {
+		if rec := recover(); rec != nil {
+			text, err = "", ErrOpenPdfFile
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", ErrOpenPdfFile
+	}
+
+	return extractPdfText(r), nil
+}
+
+func extractDocx(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", ErrInvalidDocx
+	}
+
+	var document *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			document = f
+			break
+		}
+	}
+	if document == nil {
+		return "", ErrInvalidDocx
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", ErrInvalidDocx
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	decoder := xml.NewDecoder(rc)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", ErrInvalidDocx
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return normalizeText(sb.String()), nil
+}
+
+var (
+	markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownFence = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+)
+
+// extractMarkdown keeps headings and lists since they carry the CV structure, only
+// images, link targets and code fences are simplified.
+func extractMarkdown(data []byte) (string, error) {
+	text := string(data)
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	text = markdownFence.ReplaceAllString(text, "")
+	return normalizeText(text), nil
+}
+
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "tr": true, "table": true, "section": true,
+	"article": true, "header": true, "footer": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+func extractHtml(data []byte) (string, error) {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return normalizeText(sb.String()), nil
+			}
+			return "", tokenizer.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "script" || tag == "style" || tag == "noscript" {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if tag == "li" {
+				sb.WriteString("\n- ")
+			} else if htmlBlockElements[tag] {
+				sb.WriteString("\n")
+			} else if tag == "td" || tag == "th" {
+				sb.WriteString("\t")
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "script" || tag == "style" || tag == "noscript" {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if htmlBlockElements[tag] {
+				sb.WriteString("\n")
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				sb.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+func extractPlainText(data []byte) (string, error) {
+	if !utf8.Valid(data) {
+		return "", ErrUnsupportedContentType
+	}
+	return normalizeText(string(data)), nil
+}
+
+// rtf destinations that hold metadata instead of document text
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true,
+	"pict": true, "header": true, "footer": true, "listtable": true, "listoverridetable": true,
+}
+
+func extractRtf(data []byte) (string, error) {
+	var sb strings.Builder
+	// each group remembers whether its content is skipped
+	skipStack := []bool{false}
+	skipping := func() bool { return skipStack[len(skipStack)-1] }
+
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		switch ch {
+		case '{':
+			skipStack = append(skipStack, skipping())
+		case '}':
+			if len(skipStack) > 1 {
+				skipStack = skipStack[:len(skipStack)-1]
+			}
+		case '\\':
+			if i+1 >= len(data) {
+				continue
+			}
+			next := data[i+1]
+
+			switch {
+			case next == '\\' || next == '{' || next == '}':
+				if !skipping() {
+					sb.WriteByte(next)
+				}
+				i++
+			case next == '*':
+				skipStack[len(skipStack)-1] = true
+				i++
+			case next == '\'':
+				if i+3 < len(data) {
+					if decoded, err := hex.DecodeString(string(data[i+2 : i+4])); err == nil && !skipping() {
+						sb.WriteString(string([]rune{rune(decoded[0])}))
+					}
+				}
+				i += 3
+			case isAsciiLetter(next):
+				j := i + 1
+				for j < len(data) && isAsciiLetter(data[j]) {
+					j++
+				}
+				word := string(data[i+1 : j])
+				// numeric parameter
+				if j < len(data) && (data[j] == '-' || (data[j] >= '0' && data[j] <= '9')) {
+					j++
+					for j < len(data) && data[j] >= '0' && data[j] <= '9' {
+						j++
+					}
+				}
+				// a single space delimits the control word
+				if j < len(data) && data[j] == ' ' {
+					j++
+				}
+				i = j - 1
+
+				if rtfSkippedDestinations[word] {
+					skipStack[len(skipStack)-1] = true
+					continue
+				}
+				if skipping() {
+					continue
+				}
+				switch word {
+				case "par", "line", "row":
+					sb.WriteString("\n")
+				case "tab", "cell":
+					sb.WriteString("\t")
+				}
+			default:
+				i++
+			}
+		case '\r', '\n':
+			continue
+		default:
+			if !skipping() {
+				sb.WriteByte(ch)
+			}
+		}
+	}
+
+	return normalizeText(sb.String()), nil
+}
+
+func isAsciiLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+var (
+	horizontalSpace = regexp.MustCompile(`[ \t\f\v\p{Zs}]+`)
+	manyNewLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalizeText collapses horizontal whitespace but keeps line breaks, so headings
+// and list items of text based formats survive extraction.
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+
+	text = strings.Join(lines, "\n")
+	text = manyNewLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -1,14 +1,21 @@
 package ingestdocument
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
 	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+	ocrengine "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ocr-engine"
 	"github.com/ledongthuc/pdf"
 )
 
@@ -21,12 +28,18 @@ var (
 )
 
 type ChunkingConfig struct {
+	Strategy      ChunkStrategy
 	WordsPerChunk int
 	OverlapWords  int
+	// MaxTokens sizes chunks with Tokenizer instead of WordsPerChunk when set
+	MaxTokens     int
+	OverlapTokens int
+	Tokenizer     ITokenizer
 }
 
 func WithDefaultChunkConfig() ChunkingConfig {
 	return ChunkingConfig{
+		Strategy:      ChunkByWords,
 		WordsPerChunk: 150,
 		OverlapWords:  20,
 	}
@@ -35,30 +48,79 @@ func WithDefaultChunkConfig() ChunkingConfig {
 type IngestOptions struct {
 	ChunkingConfig ChunkingConfig
 	BatchSize      int
+	// Concurrency is how many batches are upserted at the same time
+	Concurrency int
+	// Version labels the ingested content, defaults to the version number
+	Version string
+	// RetainVersions is how many versions of a document stay stored, the new one included,
+	// 0 keeps every version so evaluations can pin any of them
+	RetainVersions int
 }
 
+// DefaultRetainVersions keeps the recent versions pinnable without growing the
+// collections on every re-ingest.
+const DefaultRetainVersions = 5
+
 func WithDefaultIngestOptions() IngestOptions {
 	return IngestOptions{
 		ChunkingConfig: WithDefaultChunkConfig(),
 		BatchSize:      10,
+		Concurrency:    4,
+		RetainVersions: DefaultRetainVersions,
 	}
 }
 
+// IngestChunkError lists the chunks that were not stored, the other batches of the same
+// document are kept.
+type IngestChunkError struct {
+	CollectionName string
+	DocId          string
+	FailedIds      []string
+	TotalChunks    int
+	Err            error
+}
+
+func (e *IngestChunkError) Error() string {
+	return fmt.Sprintf("failed to upsert %d of %d chunks of %s into %s: %s", len(e.FailedIds), e.TotalChunks, e.DocId, e.CollectionName, strings.Join(e.FailedIds, ", "))
+}
+
+func (e *IngestChunkError) Unwrap() error {
+	return e.Err
+}
+
 // Ingest
 type IIngestFile interface {
 	ExtractTextFromPdf(path string) (string, error)
+	ExtractText(ctx context.Context, path string) (*ExtractionResult, error)
+	RegisterExtractor(contentType string, extractor ITextExtractor)
 	ChunkText(text string, config ChunkingConfig) []string
 	IngestToChroma(ctx context.Context, collectionName, docId, content string, metadata map[string]interface{}, option IngestOptions) error
+	BackfillLatest(ctx context.Context, collectionName string) (int, error)
 }
 
+type IngestFileOption func(*ingestFile)
+
 type ingestFile struct {
-	chroma chromaclient.IChromaClient
+	chroma      chromaclient.IChromaClient
+	extractors  map[string]ITextExtractor
+	ocr         ocrengine.IOcrEngine
+	ocrMinChars int
+	pdfMode     PdfExtractionMode
 }
 
-func NewIngestFile(chroma chromaclient.IChromaClient) IIngestFile {
-	return &ingestFile{
-		chroma: chroma,
+func NewIngestFile(chroma chromaclient.IChromaClient, opts ...IngestFileOption) IIngestFile {
+	ingest := &ingestFile{
+		chroma:      chroma,
+		extractors:  defaultExtractors(),
+		ocrMinChars: DefaultOcrMinCharsPerPage,
+		pdfMode:     PdfExtractionPlain,
+	}
+
+	for _, opt := range opts {
+		opt(ingest)
 	}
+
+	return ingest
 }
 
 func (i *ingestFile) ExtractTextFromPdf(path string) (string, error) {
@@ -68,73 +130,124 @@ func (i *ingestFile) ExtractTextFromPdf(path string) (string, error) {
 	}
 	defer f.Close()
 
-	var sb strings.Builder
-	totalPage := r.NumPage()
-
-	for page := 1; page <= totalPage; page++ {
-		p := r.Page(page)
-		if p.V.IsNull() || p.V.Key("Contents").Kind() == pdf.Null {
-			continue
-		}
+	return extractPdfText(r), nil
+}
 
-		content, err := p.GetPlainText(nil)
-		if err != nil {
-			fmt.Println("error when get the text from file")
-			continue
-		}
+// ExtractText sniffs the document type and dispatches to the registered extractor.
+func (i *ingestFile) ExtractText(ctx context.Context, path string) (*ExtractionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ErrReadDocumentFile
+	}
 
-		sb.WriteString(strings.TrimSpace(content))
-		sb.WriteString(" ")
+	contentType := SniffContentType(data, path)
+	if contentType == ContentTypePdf && (i.ocr != nil || i.pdfMode == PdfExtractionLayout) {
+		return i.extractPdfDocument(ctx, path, data)
 	}
 
-	text := sb.String()
+	extractor, ok := i.extractors[contentType]
+	if !ok {
+		return nil, ErrUnsupportedContentType
+	}
 
-	space := regexp.MustCompile(`\s+`)
-	text = space.ReplaceAllString(text, " ")
+	text, err := extractor.Extract(data)
+	if err != nil {
+		return nil, err
+	}
 
-	return strings.TrimSpace(text), nil
+	return &ExtractionResult{
+		ContentType: contentType,
+		Text:        text,
+	}, nil
 }
 
-func (i *ingestFile) ChunkText(text string, config ChunkingConfig) []string {
-	// sanitize config
-	if config.WordsPerChunk <= 0 {
-		config.WordsPerChunk = 150
-	}
-	if config.OverlapWords < 0 {
-		config.OverlapWords = 0
+// extractPdfDocument is the PDF path used when OCR fallback or layout extraction is
+// enabled, both need the text page by page.
+func (i *ingestFile) extractPdfDocument(ctx context.Context, path string, data []byte) (result *ExtractionResult, err error) {
+	// the pdf reader panics on some malformed documents
+	defer func() {
+		if rec := recover(); rec != nil {
+			result, err = nil, ErrOpenPdfFile
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrOpenPdfFile
 	}
-	if config.OverlapWords >= config.WordsPerChunk {
-		config.OverlapWords = config.WordsPerChunk / 2
+
+	pages := extractPdfPages(r)
+	ocr := i.recognizeLowYieldPages(ctx, path, pages)
+	for page, text := range ocr.pageTexts {
+		pages[page-1] = text
 	}
 
-	text = strings.TrimSpace(text)
-	if text == "" {
-		return []string{}
+	result = &ExtractionResult{
+		ContentType: ContentTypePdf,
+		OcrPages:    ocr.pages,
+	}
+	if len(ocr.pages) > 0 {
+		result.OcrEngine = i.ocr.Name()
+		result.OcrConfidence = ocr.confidence
 	}
 
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{}
+	if i.pdfMode == PdfExtractionLayout {
+		result.Structured = extractPdfLayout(r, ocr.pageTexts)
+		result.Text = result.Structured.PlainText()
+		return result, nil
 	}
 
-	var chunks []string
-	step := config.WordsPerChunk - config.OverlapWords
+	text := strings.Join(pages, " ")
+	space := regexp.MustCompile(`\s+`)
+	result.Text = strings.TrimSpace(space.ReplaceAllString(text, " "))
+
+	return result, nil
+}
 
-	for i := 0; i < len(words); i += step {
-		end := i + config.WordsPerChunk
-		if end > len(words) {
-			end = len(words)
-		}
+func (i *ingestFile) RegisterExtractor(contentType string, extractor ITextExtractor) {
+	i.extractors[contentType] = extractor
+}
 
-		chunk := strings.Join(words[i:end], " ")
-		chunks = append(chunks, chunk)
+func extractPdfText(r *pdf.Reader) string {
+	text := strings.Join(extractPdfPages(r), " ")
 
-		if end == len(words) {
-			break
+	space := regexp.MustCompile(`\s+`)
+	text = space.ReplaceAllString(text, " ")
+
+	return strings.TrimSpace(text)
+}
+
+// extractPdfPages returns the plain text of every page, index 0 is page 1.
+func extractPdfPages(r *pdf.Reader) []string {
+	totalPage := r.NumPage()
+	pages := make([]string, totalPage)
+
+	for page := 1; page <= totalPage; page++ {
+		p := r.Page(page)
+		if p.V.IsNull() || p.V.Key("Contents").Kind() == pdf.Null {
+			continue
+		}
+
+		content, err := p.GetPlainText(nil)
+		if err != nil {
+			fmt.Println("error when get the text from file")
+			continue
 		}
+
+		pages[page-1] = strings.TrimSpace(content)
 	}
 
-	return chunks
+	return pages
+}
+
+func (i *ingestFile) ChunkText(text string, config ChunkingConfig) []string {
+	chunks := chunkDocument(text, config)
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.text)
+	}
+	return texts
 }
 
 func (i *ingestFile) IngestToChroma(ctx context.Context, collectionName, docId, content string, metadata map[string]interface{}, options IngestOptions) error {
@@ -148,15 +261,51 @@ func (i *ingestFile) IngestToChroma(ctx context.Context, collectionName, docId,
 		return ErrIngestContentEmpty
 	}
 
-	chunks := i.ChunkText(content, options.ChunkingConfig)
+	chunkingConfig := options.ChunkingConfig.sanitize()
+	hash := contentHash(content, chunkingConfig)
+
+	previous, err := i.documentVersions(ctx, collectionName, docId)
+	if err != nil {
+		return err
+	}
+
+	label := options.Version
+	if label == "" {
+		if value, ok := metadata[MetadataVersion].(string); ok {
+			label = value
+		}
+	}
+
+	number := 1
+	if len(previous) > 0 {
+		// the newest version may be a leftover that was never switched to latest
+		for idx := len(previous) - 1; idx >= 0; idx-- {
+			latest := previous[idx]
+			if !latest.latest {
+				continue
+			}
+			if latest.hash == hash && (label == "" || label == latest.label) {
+				fmt.Printf("skip ingest %s in %s, content unchanged since version %s\n", docId, collectionName, latest.label)
+				return nil
+			}
+			break
+		}
+		number = previous[len(previous)-1].number + 1
+	}
+	if label == "" {
+		label = strconv.Itoa(number)
+	}
+
+	chunks := chunkDocument(content, chunkingConfig)
 	if len(chunks) == 0 {
 		return errors.New("no chunks generated from content")
 	}
 
+	ingestedAt := time.Now().UTC().Format(time.RFC3339)
+
 	// Add chunk metadata
+	documents := make([]models.ChromaDocument, 0, len(chunks))
 	for idx, chunk := range chunks {
-		recID := fmt.Sprintf("%s_chunk_%d", docId, idx)
-
 		chunkMetadata := make(map[string]interface{})
 		for k, v := range metadata {
 			chunkMetadata[k] = v
@@ -164,11 +313,104 @@ func (i *ingestFile) IngestToChroma(ctx context.Context, collectionName, docId,
 		chunkMetadata["chunk_index"] = strconv.Itoa(idx)
 		chunkMetadata["total_chunks"] = strconv.Itoa(len(chunks))
 		chunkMetadata["document_id"] = docId
+		chunkMetadata["chunk_strategy"] = string(chunkingConfig.Strategy)
+		chunkMetadata["chunk_tokenizer"] = chunkingConfig.Tokenizer.Name()
+		if chunk.section != "" {
+			chunkMetadata["section_title"] = chunk.section
+		}
+		chunkMetadata[MetadataVersion] = label
+		chunkMetadata[MetadataVersionNumber] = strconv.Itoa(number)
+		chunkMetadata[MetadataContentHash] = hash
+		chunkMetadata[MetadataIngestedAt] = ingestedAt
+		// switched to latest by retireVersions once every chunk is stored
+		chunkMetadata[MetadataLatest] = "false"
+
+		documents = append(documents, models.ChromaDocument{
+			// the version is part of the id so the previous version stays intact until
+			// the new one is fully stored
+			Id:       fmt.Sprintf("%s_v%d_chunk_%d", docId, number, idx),
+			Content:  chunk.text,
+			Metadata: chunkMetadata,
+		})
+	}
+
+	failedIds, err := i.upsertBatches(ctx, collectionName, documents, options)
+	if len(failedIds) > 0 {
+		// drop the incomplete version, the previous one is still the latest
+		newIds := make([]string, 0, len(documents))
+		for _, document := range documents {
+			newIds = append(newIds, document.Id)
+		}
+		if deleteErr := i.chroma.Delete(ctx, collectionName, newIds); deleteErr != nil {
+			fmt.Printf("failed to remove incomplete version %d of %s: %s\n", number, docId, deleteErr.Error())
+		}
 
-		if err := i.chroma.Upsert(ctx, collectionName, recID, chunk, chunkMetadata); err != nil {
-			return errors.New("failed to upsert chunk")
+		return &IngestChunkError{
+			CollectionName: collectionName,
+			DocId:          docId,
+			FailedIds:      failedIds,
+			TotalChunks:    len(documents),
+			Err:            err,
 		}
 	}
 
-	return nil
+	return i.retireVersions(ctx, collectionName, documents, previous, label, options.RetainVersions)
+}
+
+// upsertBatches sends BatchSize documents per request with at most Concurrency requests
+// in flight and returns the ids of every document in a failed batch.
+func (i *ingestFile) upsertBatches(ctx context.Context, collectionName string, documents []models.ChromaDocument, options IngestOptions) ([]string, error) {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(documents)
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		failedIds []string
+		firstErr  error
+	)
+	semaphore := make(chan struct{}, concurrency)
+
+	for start := 0; start < len(documents); start += batchSize {
+		end := min(start+batchSize, len(documents))
+		batch := documents[start:end]
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(batch []models.ChromaDocument) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := i.chroma.UpsertBatch(ctx, collectionName, batch); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, document := range batch {
+					failedIds = append(failedIds, document.Id)
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	sort.Slice(failedIds, func(a, b int) bool { return chunkOrder(failedIds[a]) < chunkOrder(failedIds[b]) })
+	return failedIds, firstErr
+}
+
+// chunkOrder is the chunk index of a "{docId}_chunk_N" id.
+func chunkOrder(id string) int {
+	idx := strings.LastIndex(id, "_chunk_")
+	if idx < 0 {
+		return 0
+	}
+	order, _ := strconv.Atoi(id[idx+len("_chunk_"):])
+	return order
 }
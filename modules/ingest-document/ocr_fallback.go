@@ -0,0 +1,68 @@
+package ingestdocument
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ocrengine "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ocr-engine"
+)
+
+const DefaultOcrMinCharsPerPage = 30
+
+// WithOcrEngine enables the OCR fallback for PDF pages yielding fewer than minCharsPerPage
+// characters of text, which is what scanned CVs look like to the text extractor.
+func WithOcrEngine(engine ocrengine.IOcrEngine, minCharsPerPage int) IngestFileOption {
+	return func(i *ingestFile) {
+		i.ocr = engine
+		if minCharsPerPage > 0 {
+			i.ocrMinChars = minCharsPerPage
+		}
+	}
+}
+
+type ocrOutcome struct {
+	pageTexts  map[int]string
+	pages      []int
+	confidence float64
+}
+
+// recognizeLowYieldPages runs OCR on the pages whose extracted text is too short, pages
+// are 1-based and only replaced when OCR finds more text than the extractor did.
+func (i *ingestFile) recognizeLowYieldPages(ctx context.Context, path string, pages []string) *ocrOutcome {
+	outcome := &ocrOutcome{
+		pageTexts: make(map[int]string),
+		pages:     []int{},
+	}
+	if i.ocr == nil {
+		return outcome
+	}
+
+	var confidenceSum float64
+	for idx, pageText := range pages {
+		if len(strings.TrimSpace(pageText)) >= i.ocrMinChars {
+			continue
+		}
+
+		pageNumber := idx + 1
+		ocrResult, err := i.ocr.RecognizePdfPage(ctx, path, pageNumber)
+		if err != nil {
+			fmt.Printf("ocr failed for page %d: %s\n", pageNumber, err.Error())
+			continue
+		}
+
+		// keep the extracted text when OCR does not find more
+		if len(strings.TrimSpace(ocrResult.Text)) <= len(pageText) {
+			continue
+		}
+
+		outcome.pageTexts[pageNumber] = strings.TrimSpace(ocrResult.Text)
+		outcome.pages = append(outcome.pages, pageNumber)
+		confidenceSum += ocrResult.Confidence
+	}
+
+	if len(outcome.pages) > 0 {
+		outcome.confidence = confidenceSum / float64(len(outcome.pages))
+	}
+	return outcome
+}
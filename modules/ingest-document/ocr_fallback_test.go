@@ -0,0 +1,137 @@
+package ingestdocument
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ocrengine "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/ocr-engine"
+)
+
+const textLayerLine = "Senior backend engineer with eight years of Go and Kafka experience"
+
+// writeTestPdf writes a PDF with one page per entry, a page with empty text has no text
+// layer like a scanned page.
+func writeTestPdf(t *testing.T, pageTexts ...string) string {
+	t.Helper()
+
+	var objects []string
+	pageIds := make([]string, 0, len(pageTexts))
+	fontId := 3 + 2*len(pageTexts)
+	for idx, text := range pageTexts {
+		pageId := 3 + 2*idx
+		contentId := pageId + 1
+		pageIds = append(pageIds, fmt.Sprintf("%d 0 R", pageId))
+
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", fontId, contentId))
+		stream := ""
+		if text != "" {
+			stream = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		}
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIds, " "), len(pageTexts)),
+	}, objects...)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for idx, object := range objects {
+		offsets[idx] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", idx+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "cv.pdf")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecognizeLowYieldPages(t *testing.T) {
+	engine := ocrengine.NewStubEngine(ocrengine.OcrResult{Text: "recognized page text from the scan", Confidence: 80}, map[int]ocrengine.OcrResult{
+		3: {Text: "short", Confidence: 20},
+		4: {Text: "another recognized page of the scan", Confidence: 90},
+	})
+	ingest := NewIngestFile(nil, WithOcrEngine(engine, 20)).(*ingestFile)
+
+	pages := []string{
+		textLayerLine,
+		"",
+		"a few words",
+		"   ",
+	}
+	outcome := ingest.recognizeLowYieldPages(context.Background(), "cv.pdf", pages)
+
+	// page 1 has enough text, page 3 keeps its text since OCR found less
+	if want := []int{2, 4}; !reflect.DeepEqual(outcome.pages, want) {
+		t.Fatalf("ocr pages = %v, want %v", outcome.pages, want)
+	}
+	if outcome.pageTexts[4] != "another recognized page of the scan" {
+		t.Fatalf("page 4 text = %q", outcome.pageTexts[4])
+	}
+	if outcome.confidence != 85 {
+		t.Fatalf("confidence = %v, want the mean 85", outcome.confidence)
+	}
+}
+
+func TestRecognizeLowYieldPagesWithoutEngine(t *testing.T) {
+	ingest := NewIngestFile(nil).(*ingestFile)
+
+	outcome := ingest.recognizeLowYieldPages(context.Background(), "cv.pdf", []string{""})
+	if len(outcome.pages) != 0 || len(outcome.pageTexts) != 0 {
+		t.Fatalf("outcome = %+v, want no OCR without an engine", outcome)
+	}
+}
+
+func TestExtractTextFallsBackToOcr(t *testing.T) {
+	path := writeTestPdf(t, textLayerLine, "")
+	engine := ocrengine.NewStubEngine(ocrengine.OcrResult{Text: "Scanned certificate of the AWS Solutions Architect exam", Confidence: 72.5}, nil)
+	ingest := NewIngestFile(nil, WithOcrEngine(engine, DefaultOcrMinCharsPerPage))
+
+	result, err := ingest.ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("extract: %s", err.Error())
+	}
+
+	if want := []int{2}; !reflect.DeepEqual(result.OcrPages, want) {
+		t.Fatalf("ocr pages = %v, want %v", result.OcrPages, want)
+	}
+	if result.OcrEngine != ocrengine.EngineStub || result.OcrConfidence != 72.5 {
+		t.Fatalf("ocr engine %q confidence %v, want stub and 72.5", result.OcrEngine, result.OcrConfidence)
+	}
+	if !strings.Contains(result.Text, "Go and Kafka") || !strings.Contains(result.Text, "AWS Solutions Architect") {
+		t.Fatalf("text = %q, want the text layer and the OCR text", result.Text)
+	}
+}
+
+func TestExtractTextKeepsTextLayer(t *testing.T) {
+	path := writeTestPdf(t, textLayerLine)
+	engine := ocrengine.NewStubEngine(ocrengine.OcrResult{Text: "should not be used"}, nil)
+	ingest := NewIngestFile(nil, WithOcrEngine(engine, DefaultOcrMinCharsPerPage))
+
+	result, err := ingest.ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("extract: %s", err.Error())
+	}
+	if len(result.OcrPages) != 0 || result.OcrEngine != "" {
+		t.Fatalf("ocr pages %v engine %q, want none", result.OcrPages, result.OcrEngine)
+	}
+	if strings.Contains(result.Text, "should not be used") {
+		t.Fatalf("text = %q, OCR text used on a page with a text layer", result.Text)
+	}
+}
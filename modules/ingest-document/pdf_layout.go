@@ -0,0 +1,312 @@
+package ingestdocument
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+type PdfExtractionMode string
+
+const (
+	// PdfExtractionPlain collapses the document into a single line of text
+	PdfExtractionPlain PdfExtractionMode = "plain"
+	// PdfExtractionLayout keeps line breaks, headings and bullet lists
+	PdfExtractionLayout PdfExtractionMode = "layout"
+)
+
+type StructuredDocument struct {
+	Sections []DocumentSection `json:"sections"`
+}
+
+type DocumentSection struct {
+	Title      string   `json:"title"`
+	Level      int      `json:"level"`
+	Paragraphs []string `json:"paragraphs"`
+}
+
+func WithPdfExtractionMode(mode PdfExtractionMode) IngestFileOption {
+	return func(i *ingestFile) {
+		if mode == PdfExtractionLayout {
+			i.pdfMode = PdfExtractionLayout
+			return
+		}
+		i.pdfMode = PdfExtractionPlain
+	}
+}
+
+// Markdown renders the sections as headings followed by their paragraphs, bullet items
+// stay on consecutive lines so they read as a single list.
+func (d *StructuredDocument) Markdown() string {
+	var sb strings.Builder
+	for _, section := range d.Sections {
+		if section.Title != "" {
+			level := section.Level
+			if level < 1 {
+				level = 1
+			}
+			sb.WriteString(strings.Repeat("#", level) + " " + section.Title + "\n\n")
+		}
+
+		for idx, paragraph := range section.Paragraphs {
+			sb.WriteString(paragraph)
+			nextIsBullet := idx+1 < len(section.Paragraphs) && strings.HasPrefix(section.Paragraphs[idx+1], "- ")
+			if strings.HasPrefix(paragraph, "- ") && nextIsBullet {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// PlainText joins all titles and paragraphs with line breaks.
+func (d *StructuredDocument) PlainText() string {
+	var lines []string
+	for _, section := range d.Sections {
+		if section.Title != "" {
+			lines = append(lines, section.Title)
+		}
+		lines = append(lines, section.Paragraphs...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type layoutLine struct {
+	text     string
+	fontSize float64
+	bold     bool
+	y        float64
+	page     int
+}
+
+var (
+	bulletPrefix = regexp.MustCompile(`^(?:[•●▪■◦‣∙·\-\*–]|\d{1,2}[.)])\s+`)
+	bulletSymbol = regexp.MustCompile(`^[•●▪■◦‣∙·\-\*–]\s+`)
+)
+
+// extractPdfLayout builds a structured document from the positioned text runs of every
+// page, pageOverrides replaces the text of a page (e.g. OCR output) as plain paragraphs.
+func extractPdfLayout(r *pdf.Reader, pageOverrides map[int]string) *StructuredDocument {
+	var lines []layoutLine
+	for page := 1; page <= r.NumPage(); page++ {
+		if override, ok := pageOverrides[page]; ok {
+			for _, text := range strings.Split(override, "\n") {
+				if text = strings.TrimSpace(text); text != "" {
+					lines = append(lines, layoutLine{text: text, page: page})
+				}
+			}
+			continue
+		}
+
+		p := r.Page(page)
+		if p.V.IsNull() || p.V.Key("Contents").Kind() == pdf.Null {
+			continue
+		}
+		lines = append(lines, pageLines(p.Content().Text, page)...)
+	}
+
+	return buildStructuredDocument(lines)
+}
+
+// pageLines groups text runs sharing a baseline into lines in reading order, top to
+// bottom and left to right.
+func pageLines(runs []pdf.Text, page int) []layoutLine {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	sorted := make([]pdf.Text, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		if math.Abs(sorted[a].Y-sorted[b].Y) > lineTolerance(sorted[a], sorted[b]) {
+			return sorted[a].Y > sorted[b].Y
+		}
+		return sorted[a].X < sorted[b].X
+	})
+
+	var lines []layoutLine
+	var current []pdf.Text
+	flush := func() {
+		if line, ok := mergeRuns(current, page); ok {
+			lines = append(lines, line)
+		}
+		current = nil
+	}
+
+	for _, run := range sorted {
+		if len(current) > 0 && math.Abs(current[0].Y-run.Y) > lineTolerance(current[0], run) {
+			flush()
+		}
+		current = append(current, run)
+	}
+	flush()
+
+	return lines
+}
+
+func lineTolerance(a, b pdf.Text) float64 {
+	return math.Max(math.Max(a.FontSize, b.FontSize)*0.3, 1)
+}
+
+func mergeRuns(runs []pdf.Text, page int) (layoutLine, bool) {
+	var sb strings.Builder
+	var boldChars, totalChars int
+	var fontSize float64
+	lastEnd := math.Inf(-1)
+
+	for _, run := range runs {
+		if run.S == "" {
+			continue
+		}
+
+		// a visible gap between runs is a word break the PDF did not encode as a space
+		if sb.Len() > 0 && run.X-lastEnd > run.FontSize*0.2 && !strings.HasSuffix(sb.String(), " ") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(run.S)
+		lastEnd = run.X + run.W
+
+		chars := len([]rune(strings.TrimSpace(run.S)))
+		totalChars += chars
+		if isBoldFont(run.Font) {
+			boldChars += chars
+		}
+		fontSize = math.Max(fontSize, run.FontSize)
+	}
+
+	text := strings.Join(strings.Fields(sb.String()), " ")
+	if text == "" {
+		return layoutLine{}, false
+	}
+
+	return layoutLine{
+		text:     text,
+		fontSize: fontSize,
+		bold:     totalChars > 0 && boldChars*2 > totalChars,
+		y:        runs[0].Y,
+		page:     page,
+	}, true
+}
+
+func isBoldFont(font string) bool {
+	font = strings.ToLower(font)
+	for _, marker := range []string{"bold", "black", "heavy", "semibold", "demi"} {
+		if strings.Contains(font, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildStructuredDocument(lines []layoutLine) *StructuredDocument {
+	bodySize := bodyFontSize(lines)
+	document := &StructuredDocument{}
+	section := DocumentSection{}
+	var paragraph []string
+	var previous *layoutLine
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			section.Paragraphs = append(section.Paragraphs, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+	flushSection := func() {
+		flushParagraph()
+		if section.Title != "" || len(section.Paragraphs) > 0 {
+			document.Sections = append(document.Sections, section)
+		}
+		section = DocumentSection{}
+	}
+
+	for idx := range lines {
+		line := lines[idx]
+
+		if level := headingLevel(line, bodySize); level > 0 {
+			flushSection()
+			section.Title = strings.TrimRight(line.text, ":")
+			section.Level = level
+			previous = &lines[idx]
+			continue
+		}
+
+		if bulletPrefix.MatchString(line.text) {
+			flushParagraph()
+			paragraph = append(paragraph, "- "+bulletPrefix.ReplaceAllString(line.text, ""))
+			previous = &lines[idx]
+			continue
+		}
+
+		// a large vertical gap or a new page starts a new paragraph
+		if previous != nil && (previous.page != line.page || previous.y-line.y > math.Max(line.fontSize, previous.fontSize)*1.8) {
+			flushParagraph()
+		}
+
+		paragraph = append(paragraph, line.text)
+		previous = &lines[idx]
+	}
+	flushSection()
+
+	return document
+}
+
+// bodyFontSize is the font size covering the most characters, headings are measured
+// against it.
+func bodyFontSize(lines []layoutLine) float64 {
+	chars := make(map[float64]int)
+	for _, line := range lines {
+		if line.fontSize > 0 {
+			chars[math.Round(line.fontSize*2)/2] += len(line.text)
+		}
+	}
+
+	var bodySize float64
+	var most int
+	for size, count := range chars {
+		if count > most || (count == most && size < bodySize) {
+			bodySize, most = size, count
+		}
+	}
+	return bodySize
+}
+
+func headingLevel(line layoutLine, bodySize float64) int {
+	words := len(strings.Fields(line.text))
+	if words == 0 || words > 10 || strings.HasSuffix(line.text, ".") || bulletSymbol.MatchString(line.text) {
+		return 0
+	}
+
+	// numbered lines are headings only when set in a larger font
+	if bodySize > 0 && line.fontSize >= bodySize*1.5 {
+		return 1
+	}
+	if bodySize > 0 && line.fontSize >= bodySize*1.15 {
+		return 2
+	}
+	if bulletPrefix.MatchString(line.text) {
+		return 0
+	}
+	if line.bold || isUpperCaseTitle(line.text) {
+		return 3
+	}
+	return 0
+}
+
+func isUpperCaseTitle(text string) bool {
+	letters := 0
+	for _, ch := range text {
+		if ch >= 'a' && ch <= 'z' {
+			return false
+		}
+		if ch >= 'A' && ch <= 'Z' {
+			letters++
+		}
+	}
+	return letters >= 3
+}
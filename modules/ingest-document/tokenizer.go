@@ -0,0 +1,54 @@
+package ingestdocument
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ITokenizer counts tokens the way the embedding or generation model would, chunk sizes
+// set in tokens are measured with it.
+type ITokenizer interface {
+	Name() string
+	CountTokens(text string) int
+}
+
+type wordTokenizer struct{}
+
+// NewWordTokenizer counts whitespace separated words.
+func NewWordTokenizer() ITokenizer {
+	return &wordTokenizer{}
+}
+
+func (t *wordTokenizer) Name() string { return "words" }
+
+func (t *wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+type approxTokenizer struct{}
+
+// NewApproxTokenizer estimates subword tokens, about four characters per token for words
+// plus one token per punctuation mark, close to what Gemini reports for English text.
+func NewApproxTokenizer() ITokenizer {
+	return &approxTokenizer{}
+}
+
+func (t *approxTokenizer) Name() string { return "approx" }
+
+func (t *approxTokenizer) CountTokens(text string) int {
+	tokens := 0
+	for _, word := range strings.Fields(text) {
+		letters := 0
+		for _, ch := range word {
+			if unicode.IsPunct(ch) || unicode.IsSymbol(ch) {
+				tokens++
+				continue
+			}
+			letters++
+		}
+		if letters > 0 {
+			tokens += (letters + 3) / 4
+		}
+	}
+	return tokens
+}
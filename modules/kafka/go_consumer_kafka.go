@@ -38,6 +38,12 @@ type ConsumerController interface {
 	ProcessMessage(ctx context.Context, msg *Message) error
 }
 
+// Gate blocks message processing until it may continue, e.g. while a downstream
+// circuit breaker is open
+type Gate interface {
+	Wait(ctx context.Context) error
+}
+
 type ConsumerOption func(*consumerConfig)
 
 type consumerConfig struct {
@@ -48,6 +54,7 @@ type consumerConfig struct {
 	saramaConfig *sarama.Config
 	retryPolicy  RetryPolicy
 	ackMode      AckMode
+	gate         Gate
 }
 
 type RetryPolicy struct {
@@ -90,7 +97,7 @@ func NewConsumer(opts ...ConsumerOption) (*Consumer, error) {
 	return &Consumer{
 		group:     group,
 		topics:    config.topics,
-		handler:   newConsumerHandler(config.controller, config.retryPolicy, config.ackMode),
+		handler:   newConsumerHandler(config.controller, config.retryPolicy, config.ackMode, config.gate),
 		closeChan: make(chan struct{}),
 	}, nil
 }
@@ -127,13 +134,15 @@ type consumerHandler struct {
 	controller  ConsumerController
 	retryPolicy RetryPolicy
 	ackMode     AckMode
+	gate        Gate
 }
 
-func newConsumerHandler(controller ConsumerController, retryPolicy RetryPolicy, ackMode AckMode) *consumerHandler {
+func newConsumerHandler(controller ConsumerController, retryPolicy RetryPolicy, ackMode AckMode, gate Gate) *consumerHandler {
 	return &consumerHandler{
 		controller:  controller,
 		retryPolicy: retryPolicy,
 		ackMode:     ackMode,
+		gate:        gate,
 	}
 }
 
@@ -160,6 +169,10 @@ func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 		}
 
 		if err := h.processWithRetry(session.Context(), kafkaMsg); err != nil {
+			if session.Context().Err() != nil {
+				// the session is closing, the message is delivered again after rebalance
+				return nil
+			}
 			log.Printf("Failed to process message after retries: %v", err)
 			if h.ackMode == AckModeAuto {
 				session.MarkMessage(msg, "")
@@ -220,10 +233,22 @@ func WithAckMode(mode AckMode) ConsumerOption {
 	}
 }
 
+// WithGate pauses consumption while the gate blocks, it is checked before every attempt
+func WithGate(gate Gate) ConsumerOption {
+	return func(c *consumerConfig) {
+		c.gate = gate
+	}
+}
+
 // internal function
 func (h *consumerHandler) processWithRetry(ctx context.Context, msg *Message) error {
 	var lastErr error
 	for attempt := 1; attempt <= h.retryPolicy.MaxAttempts; attempt++ {
+		if h.gate != nil {
+			if err := h.gate.Wait(ctx); err != nil {
+				return err
+			}
+		}
 		if err := h.controller.ProcessMessage(ctx, msg); err != nil {
 			lastErr = err
 			log.Printf("Processing attempt %d failed: %v", attempt, err)
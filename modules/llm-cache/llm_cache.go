@@ -0,0 +1,78 @@
+package llmcache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	StoreOff   = "off"
+	StoreFile  = "file"
+	StoreMysql = "mysql"
+)
+
+// ICacheStore keeps model responses by cache key, an expired entry is a miss
+type ICacheStore interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, response string, ttl time.Duration) error
+}
+
+type fileEntry struct {
+	Response  string    `json:"response"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore keeps one JSON file per key in dir, expired files are removed when read.
+func NewFileStore(dir string) (ICacheStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &fileStore{dir: dir}, nil
+}
+
+func (s *fileStore) Get(ctx context.Context, key string) (string, bool, error) {
+	raw, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return "", false, err
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		_ = os.Remove(s.path(key))
+		return "", false, nil
+	}
+
+	return entry.Response, true, nil
+}
+
+func (s *fileStore) Set(ctx context.Context, key, response string, ttl time.Duration) error {
+	raw, err := json.Marshal(fileEntry{Response: response, ExpiresAt: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+
+	// written next to the entry and renamed so a reader never sees half a file
+	tmp := s.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(key))
+}
+
+func (s *fileStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key)+".json")
+}
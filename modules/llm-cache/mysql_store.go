@@ -0,0 +1,51 @@
+package llmcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mysqlStore struct {
+	db *gorm.DB
+}
+
+// NewMysqlStore keeps the responses in the llm_cache table, so every consumer replica
+// shares one cache.
+func NewMysqlStore(db *gorm.DB) ICacheStore {
+	return &mysqlStore{db: db}
+}
+
+func (s *mysqlStore) Get(ctx context.Context, key string) (string, bool, error) {
+	var entry dao.LlmCacheEntry
+	err := s.db.WithContext(ctx).Where("cache_key = ?", key).First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		s.db.WithContext(ctx).Where("cache_key = ? AND expires_at = ?", key, entry.ExpiresAt).Delete(&dao.LlmCacheEntry{})
+		return "", false, nil
+	}
+
+	return entry.Response, true, nil
+}
+
+func (s *mysqlStore) Set(ctx context.Context, key, response string, ttl time.Duration) error {
+	entry := &dao.LlmCacheEntry{
+		CacheKey:  key,
+		Response:  response,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"response", "expires_at"}),
+	}).Create(entry).Error
+}
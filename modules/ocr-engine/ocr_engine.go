@@ -0,0 +1,29 @@
+package ocrengine
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrOcrEngineUnavailable = errors.New("error ocr engine unavailable")
+	ErrOcrRasterizePage     = errors.New("error rasterize pdf page")
+	ErrOcrRecognizePage     = errors.New("error recognize pdf page")
+)
+
+const (
+	EngineNone      = "none"
+	EngineTesseract = "tesseract"
+	EngineStub      = "stub"
+)
+
+type OcrResult struct {
+	Text string
+	// Confidence is the mean word confidence between 0 and 100
+	Confidence float64
+}
+
+type IOcrEngine interface {
+	Name() string
+	RecognizePdfPage(ctx context.Context, pdfPath string, page int) (*OcrResult, error)
+}
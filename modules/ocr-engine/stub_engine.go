@@ -0,0 +1,34 @@
+package ocrengine
+
+import "context"
+
+type stubEngine struct {
+	defaultResult OcrResult
+	pages         map[int]OcrResult
+}
+
+// NewStubEngine returns the same recognized text for every page, pages can override it.
+// It is meant for tests and local runs without tesseract installed.
+func NewStubEngine(defaultResult OcrResult, pages map[int]OcrResult) IOcrEngine {
+	if pages == nil {
+		pages = make(map[int]OcrResult)
+	}
+
+	return &stubEngine{
+		defaultResult: defaultResult,
+		pages:         pages,
+	}
+}
+
+func (s *stubEngine) Name() string {
+	return EngineStub
+}
+
+func (s *stubEngine) RecognizePdfPage(ctx context.Context, pdfPath string, page int) (*OcrResult, error) {
+	if result, ok := s.pages[page]; ok {
+		return &result, nil
+	}
+
+	result := s.defaultResult
+	return &result, nil
+}
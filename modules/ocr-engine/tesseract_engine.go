@@ -0,0 +1,165 @@
+package ocrengine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type TesseractOption func(*tesseractEngine)
+
+type tesseractEngine struct {
+	tesseractPath string
+	pdftoppmPath  string
+	language      string
+	dpi           int
+}
+
+// NewTesseractEngine shells out to poppler pdftoppm to rasterize the page and to the
+// tesseract CLI to recognize it, both binaries must be available on the host.
+func NewTesseractEngine(opts ...TesseractOption) (IOcrEngine, error) {
+	engine := &tesseractEngine{
+		tesseractPath: "tesseract",
+		pdftoppmPath:  "pdftoppm",
+		language:      "eng",
+		dpi:           300,
+	}
+
+	for _, opt := range opts {
+		opt(engine)
+	}
+
+	if _, err := exec.LookPath(engine.tesseractPath); err != nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrOcrEngineUnavailable, engine.tesseractPath)
+	}
+	if _, err := exec.LookPath(engine.pdftoppmPath); err != nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrOcrEngineUnavailable, engine.pdftoppmPath)
+	}
+
+	return engine, nil
+}
+
+// Options
+func WithTesseractPath(path string) TesseractOption {
+	return func(e *tesseractEngine) {
+		if path != "" {
+			e.tesseractPath = path
+		}
+	}
+}
+
+func WithPdftoppmPath(path string) TesseractOption {
+	return func(e *tesseractEngine) {
+		if path != "" {
+			e.pdftoppmPath = path
+		}
+	}
+}
+
+func WithLanguage(language string) TesseractOption {
+	return func(e *tesseractEngine) {
+		if language != "" {
+			e.language = language
+		}
+	}
+}
+
+func WithDPI(dpi int) TesseractOption {
+	return func(e *tesseractEngine) {
+		if dpi > 0 {
+			e.dpi = dpi
+		}
+	}
+}
+
+func (e *tesseractEngine) Name() string {
+	return EngineTesseract
+}
+
+func (e *tesseractEngine) RecognizePdfPage(ctx context.Context, pdfPath string, page int) (*OcrResult, error) {
+	workDir, err := os.MkdirTemp("", "ocr-page-*")
+	if err != nil {
+		return nil, ErrOcrRasterizePage
+	}
+	defer os.RemoveAll(workDir)
+
+	pageNumber := strconv.Itoa(page)
+	imagePrefix := filepath.Join(workDir, "page")
+	rasterize := exec.CommandContext(ctx, e.pdftoppmPath,
+		"-f", pageNumber, "-l", pageNumber,
+		"-r", strconv.Itoa(e.dpi),
+		"-gray", "-singlefile", "-png",
+		pdfPath, imagePrefix,
+	)
+	if out, err := rasterize.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrOcrRasterizePage, strings.TrimSpace(string(out)))
+	}
+
+	var stdout, stderr bytes.Buffer
+	recognize := exec.CommandContext(ctx, e.tesseractPath, imagePrefix+".png", "stdout", "-l", e.language, "tsv")
+	recognize.Stdout = &stdout
+	recognize.Stderr = &stderr
+	if err := recognize.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrOcrRecognizePage, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTsv(stdout.String()), nil
+}
+
+// parseTesseractTsv rebuilds the page text from the word level rows of the tesseract
+// tsv output and averages the confidence of the recognized words.
+func parseTesseractTsv(tsv string) *OcrResult {
+	const (
+		colBlock = 2
+		colPar   = 3
+		colLine  = 4
+		colConf  = 10
+		colText  = 11
+	)
+
+	var sb strings.Builder
+	var confidenceSum float64
+	var words int
+	lastLine := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(tsv))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		cols := strings.Split(scanner.Text(), "\t")
+		if len(cols) <= colText || cols[0] == "level" {
+			continue
+		}
+
+		text := strings.TrimSpace(cols[colText])
+		confidence, err := strconv.ParseFloat(cols[colConf], 64)
+		if err != nil || confidence < 0 || text == "" {
+			continue
+		}
+
+		lineKey := cols[colBlock] + "." + cols[colPar] + "." + cols[colLine]
+		if lastLine != "" {
+			if lineKey != lastLine {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+		lastLine = lineKey
+
+		sb.WriteString(text)
+		confidenceSum += confidence
+		words++
+	}
+
+	result := &OcrResult{Text: sb.String()}
+	if words > 0 {
+		result.Confidence = confidenceSum / float64(words)
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+package piiredactor
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type RedactionLevel string
+
+const (
+	// LevelNone sends the text unchanged
+	LevelNone RedactionLevel = "none"
+	// LevelBasic replaces emails and phone numbers
+	LevelBasic RedactionLevel = "basic"
+	// LevelStandard also replaces the candidate name, addresses, dates of birth and
+	// personal profile links
+	LevelStandard RedactionLevel = "standard"
+	// LevelStrict also replaces every link, calendar date and long id number
+	LevelStrict RedactionLevel = "strict"
+)
+
+type PiiKind string
+
+const (
+	KindEmail       PiiKind = "EMAIL"
+	KindPhone       PiiKind = "PHONE"
+	KindName        PiiKind = "NAME"
+	KindAddress     PiiKind = "ADDRESS"
+	KindDateOfBirth PiiKind = "DOB"
+	KindUrl         PiiKind = "URL"
+	KindDate        PiiKind = "DATE"
+	KindIdNumber    PiiKind = "ID"
+)
+
+type IRedactor interface {
+	Level() RedactionLevel
+	// Redact replaces PII in text with placeholders recorded in vault, values already in
+	// the vault are replaced too so a name found in the CV is also hidden in the report.
+	Redact(text string, vault *Vault) string
+}
+
+type redactor struct {
+	level RedactionLevel
+}
+
+func NewRedactor(level RedactionLevel) IRedactor {
+	switch level {
+	case LevelNone, LevelBasic, LevelStrict:
+	default:
+		level = LevelStandard
+	}
+
+	return &redactor{level: level}
+}
+
+var (
+	emailPattern        = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern        = regexp.MustCompile(`\+?\d[\d \t().\-]{7,}\d`)
+	yearRangePattern    = regexp.MustCompile(`^\d{4}\s*[-–]\s*\d{4}$`)
+	profileUrlPattern   = regexp.MustCompile(`(?i)\b(?:https?://)?(?:www\.)?(?:linkedin\.com|github\.com|gitlab\.com|twitter\.com|x\.com|facebook\.com|instagram\.com|medium\.com)/[^\s)\]>,]+`)
+	urlPattern          = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s)\]>,]+`)
+	nameLabelPattern    = regexp.MustCompile(`(?im)^[ \t#*]*(?:full\s+)?name\s*[:\-]\s*([^\n]+)$`)
+	addressLabelPattern = regexp.MustCompile(`(?im)^[ \t#*]*(?:home\s+)?(?:address|domicile|alamat)\s*[:\-]\s*([^\n]+)$`)
+	streetPattern       = regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][a-z]+\s+){1,4}(?:Street|St|Road|Rd|Avenue|Ave|Lane|Ln|Boulevard|Blvd|Drive|Dr)\b\.?|\bJl\.?\s+[^,\n]+`)
+	dobPattern          = regexp.MustCompile(`(?i)(?:date\s+of\s+birth|birth\s*date|d\.?o\.?b\.?|born(?:\s+on)?|tanggal\s+lahir)\s*[:\-]?\s*([^\n]+)`)
+	datePattern         = regexp.MustCompile(`(?i)\b\d{1,2}[/.\-]\d{1,2}[/.\-]\d{2,4}\b|\b\d{1,2}\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{4}\b`)
+	idNumberPattern     = regexp.MustCompile(`\b\d{6,}\b`)
+	nameWordPattern     = regexp.MustCompile(`^\p{Lu}[\p{L}'.\-]*$`)
+)
+
+// header lines holding one of these words are titles, not the candidate name
+var notNameWords = []string{"curriculum", "vitae", "resume", "résumé", "cv", "profile", "summary", "contact", "experience", "education", "skills", "report"}
+
+func (r *redactor) Level() RedactionLevel {
+	return r.level
+}
+
+func (r *redactor) Redact(text string, vault *Vault) string {
+	if r.level == LevelNone || text == "" {
+		return text
+	}
+
+	text = vault.replaceKnown(text)
+	text = replacePattern(text, emailPattern, KindEmail, vault, nil)
+
+	if r.level == LevelStrict {
+		text = replacePattern(text, urlPattern, KindUrl, vault, nil)
+	}
+	if r.level == LevelStandard || r.level == LevelStrict {
+		text = replacePattern(text, profileUrlPattern, KindUrl, vault, nil)
+		text = replaceLabelled(text, dobPattern, KindDateOfBirth, vault)
+		text = replaceLabelled(text, addressLabelPattern, KindAddress, vault)
+		text = replacePattern(text, streetPattern, KindAddress, vault, nil)
+		text = r.redactNames(text, vault)
+	}
+	if r.level == LevelStrict {
+		text = replacePattern(text, datePattern, KindDate, vault, nil)
+	}
+
+	text = replacePattern(text, phonePattern, KindPhone, vault, isPhoneNumber)
+
+	if r.level == LevelStrict {
+		text = replacePattern(text, idNumberPattern, KindIdNumber, vault, nil)
+	}
+
+	return text
+}
+
+// redactNames finds the candidate name from a "Name:" label or the CV header line and
+// replaces the full name and each part of it everywhere in the text.
+func (r *redactor) redactNames(text string, vault *Vault) string {
+	var names []string
+	for _, match := range nameLabelPattern.FindAllStringSubmatch(text, -1) {
+		names = append(names, strings.TrimSpace(match[1]))
+	}
+	if header := headerName(text); header != "" {
+		names = append(names, header)
+	}
+
+	for _, name := range names {
+		if strings.Contains(name, "[") || name == "" {
+			continue
+		}
+
+		placeholder := vault.placeholder(KindName, name)
+		for _, part := range strings.Fields(name) {
+			part = strings.Trim(part, ".,")
+			if len([]rune(part)) >= 3 {
+				vault.alias(part, placeholder)
+			}
+		}
+	}
+
+	text = vault.replaceKnown(text)
+
+	// "John Smith" for a known "John Michael Smith" becomes a single placeholder
+	for placeholder := range vault.Mapping() {
+		if !strings.HasPrefix(placeholder, "["+string(KindName)+"_") {
+			continue
+		}
+		for strings.Contains(text, placeholder+" "+placeholder) {
+			text = strings.ReplaceAll(text, placeholder+" "+placeholder, placeholder)
+		}
+	}
+
+	return text
+}
+
+func headerName(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.Trim(line, "#* \t"))
+		if line == "" {
+			continue
+		}
+
+		words := strings.Fields(line)
+		if len(words) < 2 || len(words) > 4 {
+			return ""
+		}
+		for _, word := range words {
+			if !nameWordPattern.MatchString(word) {
+				return ""
+			}
+			for _, notName := range notNameWords {
+				if strings.EqualFold(strings.Trim(word, ".:"), notName) {
+					return ""
+				}
+			}
+		}
+		return line
+	}
+	return ""
+}
+
+func isPhoneNumber(value string) bool {
+	if yearRangePattern.MatchString(strings.TrimSpace(value)) {
+		return false
+	}
+
+	digits := 0
+	for _, ch := range value {
+		if ch >= '0' && ch <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+func replacePattern(text string, pattern *regexp.Regexp, kind PiiKind, vault *Vault, accept func(string) bool) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if accept != nil && !accept(match) {
+			return match
+		}
+		return vault.placeholder(kind, match)
+	})
+}
+
+// replaceLabelled keeps the label, e.g. "Date of birth:", and replaces only its value.
+func replaceLabelled(text string, pattern *regexp.Regexp, kind PiiKind, vault *Vault) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		value := strings.TrimSpace(pattern.FindStringSubmatch(match)[1])
+		if value == "" || strings.HasPrefix(value, "[") {
+			return match
+		}
+		idx := strings.LastIndex(match, value)
+		return match[:idx] + vault.placeholder(kind, value) + match[idx+len(value):]
+	})
+}
+
+// sortedByLength returns the keys longest first so a full name is replaced before its parts.
+func sortedByLength(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if len(keys[a]) != len(keys[b]) {
+			return len(keys[a]) > len(keys[b])
+		}
+		return keys[a] < keys[b]
+	})
+	return keys
+}
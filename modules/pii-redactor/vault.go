@@ -0,0 +1,121 @@
+package piiredactor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Vault is the reversible mapping of one job, the same value always gets the same
+// placeholder so the model can still tell two mentions apart.
+type Vault struct {
+	mu           sync.Mutex
+	placeholders map[string]string
+	originals    map[string]string
+	counters     map[PiiKind]int
+}
+
+func NewVault() *Vault {
+	return &Vault{
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counters:     make(map[PiiKind]int),
+	}
+}
+
+// NewVaultFromMapping rebuilds a vault from a stored placeholder to original mapping.
+func NewVaultFromMapping(mapping map[string]string) *Vault {
+	vault := NewVault()
+	for placeholder, original := range mapping {
+		vault.originals[placeholder] = original
+		vault.placeholders[normalizeValue(original)] = placeholder
+	}
+	return vault
+}
+
+func (v *Vault) placeholder(kind PiiKind, value string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := normalizeValue(value)
+	if placeholder, ok := v.placeholders[key]; ok {
+		return placeholder
+	}
+
+	v.counters[kind]++
+	placeholder := fmt.Sprintf("[%s_%d]", kind, v.counters[kind])
+	v.placeholders[key] = placeholder
+	v.originals[placeholder] = strings.TrimSpace(value)
+	return placeholder
+}
+
+func (v *Vault) alias(value, placeholder string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := normalizeValue(value)
+	if _, ok := v.placeholders[key]; !ok {
+		v.placeholders[key] = placeholder
+	}
+}
+
+// replaceKnown replaces every value already in the vault, whole words only.
+func (v *Vault) replaceKnown(text string) string {
+	v.mu.Lock()
+	values := make(map[string]string, len(v.placeholders))
+	for key, placeholder := range v.placeholders {
+		values[key] = placeholder
+	}
+	v.mu.Unlock()
+
+	for _, value := range sortedByLength(values) {
+		quoted := strings.ReplaceAll(regexp.QuoteMeta(value), " ", `\s+`)
+		pattern := regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}_\[])` + quoted + `($|[^\p{L}\p{N}_\]])`)
+		// run twice, adjacent mentions share the boundary character
+		for range 2 {
+			text = pattern.ReplaceAllString(text, "${1}"+strings.ReplaceAll(values[value], "$", "$$")+"${2}")
+		}
+	}
+	return text
+}
+
+// Restore puts the original values back, e.g. in model feedback mentioning a placeholder.
+func (v *Vault) Restore(text string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	pairs := make([]string, 0, len(v.originals)*2)
+	for placeholder, original := range v.originals {
+		pairs = append(pairs, placeholder, original)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Mapping returns placeholder to original value.
+func (v *Vault) Mapping() map[string]string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	mapping := make(map[string]string, len(v.originals))
+	for placeholder, original := range v.originals {
+		mapping[placeholder] = original
+	}
+	return mapping
+}
+
+// Counts returns how many distinct values of each kind were replaced.
+func (v *Vault) Counts() map[PiiKind]int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	counts := make(map[PiiKind]int, len(v.counters))
+	for kind, count := range v.counters {
+		counts[kind] = count
+	}
+	return counts
+}
+
+func normalizeValue(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
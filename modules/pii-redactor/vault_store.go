@@ -0,0 +1,115 @@
+package piiredactor
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrVaultKeyEmpty    = errors.New("error pii vault encryption key empty")
+	ErrVaultKeyInvalid  = errors.New("error pii vault key invalid")
+	ErrVaultKeyDefault  = errors.New("error pii vault key is a placeholder, set a random secret")
+	ErrVaultNotFound    = errors.New("error pii vault not found")
+	ErrVaultSave        = errors.New("error save pii vault")
+	ErrVaultDecryptFail = errors.New("error decrypt pii vault")
+)
+
+// placeholderKeyPrefix marks example secrets such as "change-me-...", a key anyone can
+// read from a sample config protects nothing.
+const placeholderKeyPrefix = "change-me"
+
+type IVaultStore interface {
+	Save(ctx context.Context, key string, mapping map[string]string) error
+	Load(ctx context.Context, key string) (map[string]string, error)
+}
+
+type encryptedFileStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// NewEncryptedFileStore keeps one AES-256-GCM encrypted file per key in dir, the
+// encryption key is derived from secret with SHA-256.
+func NewEncryptedFileStore(dir, secret string) (IVaultStore, error) {
+	if secret == "" {
+		return nil, ErrVaultKeyEmpty
+	}
+	if strings.HasPrefix(strings.ToLower(secret), placeholderKeyPrefix) {
+		return nil, ErrVaultKeyDefault
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, ErrVaultKeyInvalid
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, ErrVaultKeyInvalid
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &encryptedFileStore{dir: dir, aead: aead}, nil
+}
+
+func (s *encryptedFileStore) Save(ctx context.Context, key string, mapping map[string]string) error {
+	plain, err := json.Marshal(mapping)
+	if err != nil {
+		return ErrVaultSave
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return ErrVaultSave
+	}
+
+	// the key is bound as additional data so a file cannot be swapped for another job
+	sealed := s.aead.Seal(nonce, nonce, plain, []byte(key))
+	if err := os.WriteFile(s.path(key), sealed, 0o600); err != nil {
+		return ErrVaultSave
+	}
+
+	return nil
+}
+
+func (s *encryptedFileStore) Load(ctx context.Context, key string) (map[string]string, error) {
+	sealed, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVaultNotFound
+		}
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, ErrVaultDecryptFail
+	}
+
+	plain, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, ErrVaultDecryptFail
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal(plain, &mapping); err != nil {
+		return nil, ErrVaultDecryptFail
+	}
+
+	return mapping, nil
+}
+
+func (s *encryptedFileStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(filepath.Clean(key))+".vault")
+}
@@ -0,0 +1,143 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("error circuit breaker is open")
+
+type CircuitState string
+
+const (
+	StateClosed   CircuitState = "closed"
+	StateOpen     CircuitState = "open"
+	StateHalfOpen CircuitState = "half-open"
+)
+
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenDuration     = time.Minute
+	// trialPollInterval is how often Wait checks whether a running trial call has ended
+	trialPollInterval = time.Second
+)
+
+// CircuitBreaker opens after consecutive provider failures, calls are refused while it is
+// open, then a single trial call decides whether it closes or opens again.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            CircuitState
+	failures         int
+	failureThreshold int
+	openDuration     time.Duration
+	openUntil        time.Time
+	trialRunning     bool
+}
+
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if openDuration <= 0 {
+		openDuration = DefaultOpenDuration
+	}
+
+	return &CircuitBreaker{
+		state:            StateClosed,
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+	}
+}
+
+// Allow returns ErrCircuitOpen while the circuit is open or its trial call is running.
+func (c *CircuitBreaker) Allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case StateOpen:
+		if time.Now().Before(c.openUntil) {
+			return ErrCircuitOpen
+		}
+		c.state = StateHalfOpen
+		c.trialRunning = true
+		return nil
+	case StateHalfOpen:
+		if c.trialRunning {
+			return ErrCircuitOpen
+		}
+		c.trialRunning = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (c *CircuitBreaker) Success() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != StateClosed {
+		log.Println("circuit breaker closed")
+	}
+	c.state = StateClosed
+	c.failures = 0
+	c.trialRunning = false
+}
+
+func (c *CircuitBreaker) Failure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures++
+	c.trialRunning = false
+	if c.state == StateHalfOpen || c.failures >= c.failureThreshold {
+		c.state = StateOpen
+		c.openUntil = time.Now().Add(c.openDuration)
+		log.Printf("circuit breaker open until %s after %d failures", c.openUntil.Format(time.RFC3339), c.failures)
+	}
+}
+
+// Release ends a call allowed by Allow that got no answer from the provider, e.g. its
+// context was cancelled. The circuit stays as it was, a half-open circuit lets the next
+// call be the trial.
+func (c *CircuitBreaker) Release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trialRunning = false
+}
+
+func (c *CircuitBreaker) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Wait blocks while the circuit is open or its trial call is running, the consumer calls
+// it before taking a message so consumption pauses instead of failing every job.
+func (c *CircuitBreaker) Wait(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		wait := time.Duration(0)
+		switch {
+		case c.state == StateOpen:
+			wait = time.Until(c.openUntil)
+			if wait > 0 {
+				log.Printf("circuit breaker is open, pausing for %s", wait.Round(time.Second))
+			}
+		case c.state == StateHalfOpen && c.trialRunning:
+			wait = trialPollInterval
+		}
+		c.mu.Unlock()
+
+		if wait <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func openBreaker(t *testing.T) *CircuitBreaker {
+	t.Helper()
+
+	breaker := NewCircuitBreaker(1, 10*time.Millisecond)
+	breaker.Failure()
+	if breaker.State() != StateOpen {
+		t.Fatalf("state = %s, want %s", breaker.State(), StateOpen)
+	}
+	time.Sleep(20 * time.Millisecond)
+	return breaker
+}
+
+func TestCircuitBreakerHalfOpenAllowsOneTrial(t *testing.T) {
+	breaker := openBreaker(t)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call refused: %v", err)
+	}
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("state = %s, want %s", breaker.State(), StateHalfOpen)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call during the trial = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	breaker.Success()
+	if breaker.State() != StateClosed {
+		t.Fatalf("state after a successful trial = %s, want %s", breaker.State(), StateClosed)
+	}
+}
+
+func TestCircuitBreakerFailedTrialOpensAgain(t *testing.T) {
+	breaker := openBreaker(t)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call refused: %v", err)
+	}
+	breaker.Failure()
+	if breaker.State() != StateOpen {
+		t.Fatalf("state after a failed trial = %s, want %s", breaker.State(), StateOpen)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call after a failed trial = %v, want %v", err, ErrCircuitOpen)
+	}
+}
+
+func TestCircuitBreakerReleasedTrialLetsNextCallTry(t *testing.T) {
+	breaker := openBreaker(t)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call refused: %v", err)
+	}
+	// the trial call was cancelled before the provider answered
+	breaker.Release()
+
+	if breaker.State() != StateHalfOpen {
+		t.Fatalf("state after a released trial = %s, want %s", breaker.State(), StateHalfOpen)
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("next trial call refused: %v", err)
+	}
+}
+
+func TestCircuitBreakerWaitBlocksDuringTrial(t *testing.T) {
+	breaker := openBreaker(t)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("trial call refused: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := breaker.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait during the trial = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	breaker.Success()
+	if err := breaker.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait after the trial = %v", err)
+	}
+}
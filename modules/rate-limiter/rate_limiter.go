@@ -0,0 +1,118 @@
+package ratelimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ILimiter interface {
+	// Wait blocks until one request with the estimated tokens fits the per-minute budgets
+	Wait(ctx context.Context, tokens int) error
+	// Adjust corrects the tokens taken by Wait once the real usage is known
+	Adjust(ctx context.Context, delta int)
+}
+
+type bucket struct {
+	capacity  float64
+	available float64
+	perSecond float64
+	updated   time.Time
+}
+
+func newBucket(perMinute int) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &bucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+		updated:   time.Now(),
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.available = min(b.capacity, b.available+now.Sub(b.updated).Seconds()*b.perSecond)
+	b.updated = now
+}
+
+// waitFor is how long until amount is available, amounts over the capacity only wait for
+// a full bucket.
+func (b *bucket) waitFor(amount float64) time.Duration {
+	amount = min(amount, b.capacity)
+	if b.available >= amount {
+		return 0
+	}
+	return time.Duration((amount - b.available) / b.perSecond * float64(time.Second))
+}
+
+type localLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+}
+
+// NewLocalLimiter keeps requests and tokens per minute buckets in this process, a zero
+// limit is not enforced.
+func NewLocalLimiter(requestsPerMinute, tokensPerMinute int) ILimiter {
+	return &localLimiter{
+		requests: newBucket(requestsPerMinute),
+		tokens:   newBucket(tokensPerMinute),
+	}
+}
+
+func (l *localLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait := time.Duration(0)
+		if l.requests != nil {
+			l.requests.refill(now)
+			wait = max(wait, l.requests.waitFor(1))
+		}
+		if l.tokens != nil {
+			l.tokens.refill(now)
+			wait = max(wait, l.tokens.waitFor(float64(tokens)))
+		}
+
+		if wait == 0 {
+			if l.requests != nil {
+				l.requests.available--
+			}
+			if l.tokens != nil {
+				l.tokens.available -= min(float64(tokens), l.tokens.capacity)
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *localLimiter) Adjust(ctx context.Context, delta int) {
+	if l.tokens == nil || delta == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.available = min(l.tokens.capacity, l.tokens.available-float64(delta))
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models/dao"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errWindowFull = errors.New("error rate window is full")
+
+type sharedLimiter struct {
+	db                *gorm.DB
+	key               string
+	requestsPerMinute int
+	tokensPerMinute   int
+}
+
+// NewSharedLimiter counts requests and tokens in per-minute windows stored in the
+// database, so every consumer replica using the same key shares one budget.
+func NewSharedLimiter(db *gorm.DB, key string, requestsPerMinute, tokensPerMinute int) ILimiter {
+	return &sharedLimiter{
+		db:                db,
+		key:               key,
+		requestsPerMinute: requestsPerMinute,
+		tokensPerMinute:   tokensPerMinute,
+	}
+}
+
+func (s *sharedLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		window := time.Now().UTC().Truncate(time.Minute)
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dao.LlmRateWindow{
+				LimiterKey:  s.key,
+				WindowStart: window,
+			})
+			if created.Error != nil {
+				return created.Error
+			}
+			// the first call of a minute removes the expired windows of every key, once per
+			// minute and key keeps the table to the current windows
+			if created.RowsAffected > 0 {
+				if err := tx.Where("window_start < ?", window).Delete(&dao.LlmRateWindow{}).Error; err != nil {
+					return err
+				}
+			}
+
+			var current dao.LlmRateWindow
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("limiter_key = ? AND window_start = ?", s.key, window).
+				First(&current).Error; err != nil {
+				return err
+			}
+
+			if s.requestsPerMinute > 0 && current.Requests+1 > s.requestsPerMinute {
+				return errWindowFull
+			}
+			// a request bigger than the whole budget still runs alone in an empty window
+			if s.tokensPerMinute > 0 && current.Tokens > 0 && current.Tokens+tokens > s.tokensPerMinute {
+				return errWindowFull
+			}
+
+			return tx.Model(&current).
+				Where("limiter_key = ? AND window_start = ?", s.key, window).
+				Updates(map[string]interface{}{
+					"requests": gorm.Expr("requests + 1"),
+					"tokens":   gorm.Expr("tokens + ?", tokens),
+				}).Error
+		})
+
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errWindowFull) {
+			// the database being unavailable must not stop the evaluations
+			log.Printf("shared rate limiter unavailable, continuing without it: %s", err.Error())
+			return nil
+		}
+
+		if err := sleep(ctx, time.Until(window.Add(time.Minute))); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *sharedLimiter) Adjust(ctx context.Context, delta int) {
+	if delta == 0 {
+		return
+	}
+
+	window := time.Now().UTC().Truncate(time.Minute)
+	err := s.db.WithContext(ctx).Model(&dao.LlmRateWindow{}).
+		Where("limiter_key = ? AND window_start = ?", s.key, window).
+		Update("tokens", gorm.Expr("GREATEST(tokens + ?, 0)", delta)).Error
+	if err != nil {
+		log.Printf("failed to adjust shared rate limiter tokens: %s", err.Error())
+	}
+}
@@ -0,0 +1,170 @@
+package retriever
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+)
+
+const (
+	DefaultIndexRefresh = 5 * time.Minute
+	bm25K1              = 1.2
+	bm25B               = 0.75
+)
+
+type IKeywordIndex interface {
+	Search(ctx context.Context, collectionName, query string, topK int, opts ...chromaclient.QueryOption) ([]models.ChromaSearchResult, error)
+}
+
+type bm25Document struct {
+	record models.ChromaSearchResult
+	terms  map[string]int
+	length int
+}
+
+type bm25Collection struct {
+	documents     []bm25Document
+	documentFreq  map[string]int
+	averageLength float64
+	loadedAt      time.Time
+}
+
+type bm25Index struct {
+	chroma  chromaclient.IChromaClient
+	refresh time.Duration
+
+	mu          sync.Mutex
+	collections map[string]*bm25Collection
+}
+
+// NewBM25Index keeps an in-process keyword index of every chunk in a collection, loaded
+// from Chroma on first use and reloaded once older than refresh. Documents are ingested
+// by another process, so a re-ingest is only seen after the next reload.
+func NewBM25Index(chroma chromaclient.IChromaClient, refresh time.Duration) IKeywordIndex {
+	if refresh <= 0 {
+		refresh = DefaultIndexRefresh
+	}
+
+	return &bm25Index{
+		chroma:      chroma,
+		refresh:     refresh,
+		collections: make(map[string]*bm25Collection),
+	}
+}
+
+func (b *bm25Index) Search(ctx context.Context, collectionName, query string, topK int, opts ...chromaclient.QueryOption) ([]models.ChromaSearchResult, error) {
+	collection, err := b.load(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	queryTerms := tokenize(query)
+	total := float64(len(collection.documents))
+	var results []models.ChromaSearchResult
+	for _, document := range collection.documents {
+		if !chromaclient.MatchMetadata(document.record.Metadata, opts...) {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range queryTerms {
+			freq := float64(document.terms[term])
+			if freq == 0 {
+				continue
+			}
+			docFreq := float64(collection.documentFreq[term])
+			idf := math.Log(1 + (total-docFreq+0.5)/(docFreq+0.5))
+			norm := 1 - bm25B + bm25B*float64(document.length)/collection.averageLength
+			score += idf * freq * (bm25K1 + 1) / (freq + bm25K1*norm)
+		}
+		if score <= 0 {
+			continue
+		}
+
+		result := document.record
+		result.Distance = 0
+		result.Score = score / (score + 1)
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(a, b int) bool { return results[a].Score > results[b].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+
+	return results, nil
+}
+
+func (b *bm25Index) load(ctx context.Context, collectionName string) (*bm25Collection, error) {
+	b.mu.Lock()
+	collection, ok := b.collections[collectionName]
+	b.mu.Unlock()
+	if ok && time.Since(collection.loadedAt) < b.refresh {
+		return collection, nil
+	}
+
+	documents, err := b.chroma.Get(ctx, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	collection = &bm25Collection{
+		documentFreq: make(map[string]int),
+		loadedAt:     time.Now(),
+	}
+	totalLength := 0
+	for _, document := range documents {
+		terms := make(map[string]int)
+		tokens := tokenize(document.Content)
+		for _, token := range tokens {
+			terms[token]++
+		}
+		for term := range terms {
+			collection.documentFreq[term]++
+		}
+		totalLength += len(tokens)
+
+		collection.documents = append(collection.documents, bm25Document{
+			record: models.ChromaSearchResult{
+				Id:       document.Id,
+				Text:     document.Content,
+				Metadata: document.Metadata,
+			},
+			terms:  terms,
+			length: len(tokens),
+		})
+	}
+	if len(documents) > 0 {
+		collection.averageLength = float64(totalLength) / float64(len(documents))
+	}
+	if collection.averageLength == 0 {
+		collection.averageLength = 1
+	}
+
+	b.mu.Lock()
+	b.collections[collectionName] = collection
+	b.mu.Unlock()
+
+	return collection, nil
+}
+
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(ch rune) bool {
+		return !unicode.IsLetter(ch) && !unicode.IsDigit(ch)
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) >= 2 {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,148 @@
+package retriever
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+const (
+	RerankerNone         = "none"
+	RerankerLlm          = "llm"
+	RerankerCrossEncoder = "cross-encoder"
+)
+
+var ErrInvalidRerankResponse = errors.New("invalid rerank response")
+
+type IReranker interface {
+	// Rerank orders results by relevance to query and sets Score between 0 and 1, opts
+	// apply to the model call of a model based re-ranker.
+	Rerank(ctx context.Context, jobTitle, query string, results []models.ChromaSearchResult, opts ...geminiclient.GenerateOption) ([]models.ChromaSearchResult, error)
+}
+
+type rerankScore struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+type llmReranker struct {
+	gemini geminiclient.IGeminiClient
+}
+
+// NewLlmReranker asks the generation model to grade every passage from 0 to 10.
+func NewLlmReranker(gemini geminiclient.IGeminiClient) IReranker {
+	return &llmReranker{gemini: gemini}
+}
+
+func (l *llmReranker) Rerank(ctx context.Context, jobTitle, query string, results []models.ChromaSearchResult, opts ...geminiclient.GenerateOption) ([]models.ChromaSearchResult, error) {
+	prompt := "Grade how relevant each passage is to the query, from 0 (unrelated) to 10 (directly answers it).\n"
+	prompt += "Query: " + query + "\n"
+	prompt += "\n-----\n"
+	for idx, result := range results {
+		prompt += fmt.Sprintf("[%d] %s\n\n", idx, result.Text)
+	}
+	prompt += "\n-----\n"
+	prompt += "Return only JSON as:\n[{\"index\": 0, \"score\": 0}]\n"
+
+	rerankOpts := append(slices.Clone(opts), geminiclient.WithResponseMIMEType("application/json"))
+	resp, err := l.gemini.GenerateContent(ctx, jobTitle, prompt, rerankOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	start := strings.Index(resp, "[")
+	end := strings.LastIndex(resp, "]")
+	if start < 0 || end < start {
+		return nil, ErrInvalidRerankResponse
+	}
+
+	var scores []rerankScore
+	if err := json.Unmarshal([]byte(resp[start:end+1]), &scores); err != nil {
+		return nil, ErrInvalidRerankResponse
+	}
+	for idx := range scores {
+		scores[idx].Score /= 10
+	}
+
+	return applyRerankScores(results, scores)
+}
+
+type crossEncoderReranker struct {
+	url    string
+	client *http.Client
+}
+
+// NewCrossEncoderReranker calls a rerank endpoint taking {"query", "texts"} and returning
+// [{"index", "score"}], the API of text-embeddings-inference /rerank.
+func NewCrossEncoderReranker(url string) IReranker {
+	return &crossEncoderReranker{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *crossEncoderReranker) Rerank(ctx context.Context, jobTitle, query string, results []models.ChromaSearchResult, opts ...geminiclient.GenerateOption) ([]models.ChromaSearchResult, error) {
+	texts := make([]string, 0, len(results))
+	for _, result := range results {
+		texts = append(texts, result.Text)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "texts": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank endpoint returned status %d", resp.StatusCode)
+	}
+
+	var scores []rerankScore
+	if err := json.NewDecoder(resp.Body).Decode(&scores); err != nil {
+		return nil, ErrInvalidRerankResponse
+	}
+
+	return applyRerankScores(results, scores)
+}
+
+// applyRerankScores sorts by the new scores, passages the re-ranker skipped keep their
+// place after the graded ones with a zero score.
+func applyRerankScores(results []models.ChromaSearchResult, scores []rerankScore) ([]models.ChromaSearchResult, error) {
+	graded := make(map[int]float64, len(scores))
+	for _, score := range scores {
+		if score.Index < 0 || score.Index >= len(results) {
+			return nil, ErrInvalidRerankResponse
+		}
+		graded[score.Index] = min(max(score.Score, 0), 1)
+	}
+
+	reranked := make([]models.ChromaSearchResult, len(results))
+	copy(reranked, results)
+	for idx := range reranked {
+		reranked[idx].Score = graded[idx]
+	}
+	sort.SliceStable(reranked, func(a, b int) bool { return reranked[a].Score > reranked[b].Score })
+
+	return reranked, nil
+}
@@ -0,0 +1,229 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/afrizalsebastian/ai-cv-evaluator-with-go/domain/models"
+	chromaclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/chroma-client"
+	geminiclient "github.com/afrizalsebastian/ai-cv-evaluator-with-go/modules/gemini-client"
+)
+
+type RetrievalMode string
+
+const (
+	// ModeVector only asks Chroma for the nearest chunks
+	ModeVector RetrievalMode = "vector"
+	// ModeHybrid fuses the vector results with the BM25 keyword results
+	ModeHybrid RetrievalMode = "hybrid"
+)
+
+const (
+	// DefaultCandidateMultiplier is how many more candidates than topK are fetched
+	// before fusion and re-ranking narrow them down
+	DefaultCandidateMultiplier = 3
+	rrfK                       = 60
+)
+
+type IRetriever interface {
+	Retrieve(ctx context.Context, collectionName, query string, topK int, opts ...RetrieveOption) ([]models.ChromaSearchResult, error)
+}
+
+type RetrieverOption func(*retriever)
+
+type RetrieveOption func(*retrieveConfig)
+
+type retrieveConfig struct {
+	queryOptions    []chromaclient.QueryOption
+	jobTitle        string
+	generateOptions []geminiclient.GenerateOption
+}
+
+type retriever struct {
+	chroma              chromaclient.IChromaClient
+	mode                RetrievalMode
+	keywordIndex        IKeywordIndex
+	reranker            IReranker
+	minScores           map[string]float64
+	candidateMultiplier int
+}
+
+func NewRetriever(chroma chromaclient.IChromaClient, opts ...RetrieverOption) IRetriever {
+	r := &retriever{
+		chroma:              chroma,
+		mode:                ModeVector,
+		minScores:           make(map[string]float64),
+		candidateMultiplier: DefaultCandidateMultiplier,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeHybrid && r.keywordIndex == nil {
+		r.keywordIndex = NewBM25Index(chroma, 0)
+	}
+
+	return r
+}
+
+// Options
+func WithHybrid(index IKeywordIndex) RetrieverOption {
+	return func(r *retriever) {
+		r.mode = ModeHybrid
+		r.keywordIndex = index
+	}
+}
+
+func WithReranker(reranker IReranker) RetrieverOption {
+	return func(r *retriever) {
+		r.reranker = reranker
+	}
+}
+
+// WithMinScores drops results of a collection scoring below its threshold, the score is
+// the vector relevance, the fused score in hybrid mode, or the re-ranker score.
+func WithMinScores(minScores map[string]float64) RetrieverOption {
+	return func(r *retriever) {
+		for collection, score := range minScores {
+			r.minScores[collection] = score
+		}
+	}
+}
+
+// WithCandidateMultiplier sets how many times topK candidates are fetched in hybrid mode
+// or with a re-ranker, 0 keeps DefaultCandidateMultiplier.
+func WithCandidateMultiplier(multiplier int) RetrieverOption {
+	return func(r *retriever) {
+		if multiplier > 0 {
+			r.candidateMultiplier = multiplier
+		}
+	}
+}
+
+// WithQueryOptions filters the searched chunks by metadata.
+func WithQueryOptions(opts ...chromaclient.QueryOption) RetrieveOption {
+	return func(c *retrieveConfig) {
+		c.queryOptions = append(c.queryOptions, opts...)
+	}
+}
+
+// WithRerankCall is the job a model re-ranking call is made for, opts carry its usage
+// recorder like the scoring calls of the job.
+func WithRerankCall(jobTitle string, opts ...geminiclient.GenerateOption) RetrieveOption {
+	return func(c *retrieveConfig) {
+		c.jobTitle = jobTitle
+		c.generateOptions = append(c.generateOptions, opts...)
+	}
+}
+
+func (r *retriever) Retrieve(ctx context.Context, collectionName, query string, topK int, retrieveOpts ...RetrieveOption) ([]models.ChromaSearchResult, error) {
+	retrieveCfg := &retrieveConfig{}
+	for _, opt := range retrieveOpts {
+		opt(retrieveCfg)
+	}
+	opts := retrieveCfg.queryOptions
+
+	candidates := topK
+	if r.mode == ModeHybrid || r.reranker != nil {
+		candidates = topK * r.candidateMultiplier
+	}
+
+	results, err := r.chroma.Query(ctx, collectionName, query, candidates, opts...)
+	var notFound *chromaclient.ChromaNotFoundRecord
+	if err != nil && !(r.mode == ModeHybrid && errors.As(err, &notFound)) {
+		return nil, err
+	}
+
+	if r.mode == ModeHybrid {
+		keyword, err := r.keywordIndex.Search(ctx, collectionName, query, candidates, opts...)
+		if err != nil {
+			log.Printf("keyword search on %s failed, using vector results only: %s", collectionName, err.Error())
+		} else {
+			results = reciprocalRankFusion(results, keyword)
+		}
+	}
+
+	if r.reranker != nil && len(results) > 0 {
+		reranked, err := r.reranker.Rerank(ctx, retrieveCfg.jobTitle, query, results, retrieveCfg.generateOptions...)
+		if err != nil {
+			log.Printf("re-ranking %s failed, keeping retrieval order: %s", collectionName, err.Error())
+		} else {
+			results = reranked
+		}
+	}
+
+	minScore := r.minScores[collectionName]
+	filtered := make([]models.ChromaSearchResult, 0, topK)
+	for _, result := range results {
+		if result.Score < minScore {
+			continue
+		}
+		filtered = append(filtered, result)
+		if len(filtered) == topK {
+			break
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil, &chromaclient.ChromaNotFoundRecord{CollectionName: collectionName, Query: query}
+	}
+
+	return filtered, nil
+}
+
+// reciprocalRankFusion merges ranked lists by summing 1/(k+rank), the score is scaled so
+// a chunk ranked first in every list gets 1.
+func reciprocalRankFusion(lists ...[]models.ChromaSearchResult) []models.ChromaSearchResult {
+	fused := make(map[string]*models.ChromaSearchResult)
+	scores := make(map[string]float64)
+
+	for _, list := range lists {
+		for rank, result := range list {
+			if _, ok := fused[result.Id]; !ok {
+				copied := result
+				fused[result.Id] = &copied
+			} else if result.Distance > 0 && fused[result.Id].Distance == 0 {
+				fused[result.Id].Distance = result.Distance
+			}
+			scores[result.Id] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	maxScore := float64(len(lists)) / float64(rrfK+1)
+	results := make([]models.ChromaSearchResult, 0, len(fused))
+	for id, result := range fused {
+		result.Score = scores[id] / maxScore
+		results = append(results, *result)
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Id < results[b].Id
+	})
+
+	return results
+}
+
+// ParseMinScores reads "collection:score" pairs separated by commas.
+func ParseMinScores(value string) map[string]float64 {
+	minScores := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		collection, score, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(score), 64)
+		if err != nil {
+			log.Printf("invalid minimum score for %s: %s", collection, score)
+			continue
+		}
+		minScores[strings.TrimSpace(collection)] = parsed
+	}
+	return minScores
+}